@@ -0,0 +1,93 @@
+package snowflake
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// AtomicSnowFlake 无锁的 SnowFlake，把上次时间戳和序号打包到一个 uint64 中，通过 CAS 更新
+// 高并发下可以避免 SnowFlake 的互斥锁竞争，生成的 ID 与 SnowFlake 同样唯一且递增
+type AtomicSnowFlake struct {
+	// state 高位是上次生成 ID 的时间戳（毫秒），低 SequenceBits 位是序号
+	// 放在第一个字段，保证 32 位平台上 64 位原子操作的对齐
+	state uint64
+
+	// base 只用到其中不可变的配置：开始时间、机器 ID、位分配和时间源
+	base *SnowFlake
+}
+
+// NewAtomic 创建无锁的 SnowFlake，参数含义同 NewWith
+func NewAtomic(startTime time.Time, ids ...uint8) *AtomicSnowFlake {
+	return &AtomicSnowFlake{base: NewWith(startTime, ids...)}
+}
+
+// NextID 获取一个 ID，时钟回拨时返回 *ClockBackwardsError，时间戳溢出时返回 ErrTimestampOverflow
+func (a *AtomicSnowFlake) NextID() (int64, error) {
+	return a.NextIDContext(context.Background())
+}
+
+// MustNextID 同 NextID，但出错时 panic
+func (a *AtomicSnowFlake) MustNextID() int64 {
+	id, err := a.NextID()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// NextIDSafe 同 NextID
+//
+// Deprecated: NextID 已经返回 error，直接使用 NextID
+func (a *AtomicSnowFlake) NextIDSafe() (int64, error) {
+	return a.NextID()
+}
+
+// TimeOf 同 SnowFlake.TimeOf
+func (a *AtomicSnowFlake) TimeOf(id int64) time.Time {
+	return a.base.TimeOf(id)
+}
+
+// Decompose 同 SnowFlake.Decompose
+func (a *AtomicSnowFlake) Decompose(id int64) Parts {
+	return a.base.Decompose(id)
+}
+
+// NextIDContext 获取一个 ID，等待下一毫秒时如果 ctx 被取消则返回 ctx.Err()
+func (a *AtomicSnowFlake) NextIDContext(ctx context.Context) (int64, error) {
+	l := a.base.layout
+	sequenceBits := l.config.SequenceBits
+
+	for {
+		old := atomic.LoadUint64(&a.state)
+		lastTimestamp := int64(old >> sequenceBits)
+		sequence := int64(old) & l.sequenceMask
+
+		millisecond := a.base.genTimestamp()
+		if millisecond < lastTimestamp {
+			return 0, &ClockBackwardsError{Delta: lastTimestamp - millisecond}
+		}
+
+		if millisecond == lastTimestamp {
+			// 当前毫秒内序号用完，让出 CPU 后重新读取时钟
+			if sequence == l.sequenceMask {
+				select {
+				case <-ctx.Done():
+					return 0, ctx.Err()
+				default:
+				}
+				runtime.Gosched()
+				continue
+			}
+			sequence++
+		} else {
+			sequence = 0
+		}
+
+		state := uint64(millisecond)<<sequenceBits | uint64(sequence)
+		if atomic.CompareAndSwapUint64(&a.state, old, state) {
+			return a.base.compose(millisecond, int64(sequence))
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package snowflake_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestAtomicNextIDUnique(t *testing.T) {
+	sf := snowflake.NewAtomic(testStartTime, 1, 2)
+
+	const goroutines, perGoroutine = 16, 5000
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		ids = make(map[int64]struct{}, goroutines*perGoroutine)
+	)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			local := make([]int64, perGoroutine)
+			for i := range local {
+				local[i] = sf.MustNextID()
+				// 同一个 goroutine 内必须严格递增
+				if i > 0 && local[i] <= local[i-1] {
+					t.Errorf("id %d is not greater than previous %d", local[i], local[i-1])
+					return
+				}
+			}
+
+			mu.Lock()
+			for _, id := range local {
+				ids[id] = struct{}{}
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if len(ids) != goroutines*perGoroutine {
+		t.Errorf("got %d unique ids, want %d", len(ids), goroutines*perGoroutine)
+	}
+}
+
+// BenchmarkNextIDMutex 单个 SnowFlake 在并发下使用互斥锁的基准，BenchmarkNextIDAtomic、BenchmarkNextIDParallelSharded 和 BenchmarkNextIDParallelRelaxed 都与之对比
+func BenchmarkNextIDMutex(b *testing.B) {
+	sf := snowflake.NewWith(testStartTime, 1, 2)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			sf.MustNextID()
+		}
+	})
+}
+
+func BenchmarkNextIDAtomic(b *testing.B) {
+	sf := snowflake.NewAtomic(testStartTime, 1, 2)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			sf.MustNextID()
+		}
+	})
+}
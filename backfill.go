@@ -0,0 +1,68 @@
+package snowflake
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxBackfillEntries GenerateAt 最多记录已用序号的毫秒数（使用微秒时间戳时为微秒数），
+// 避免长时间导入历史数据时占用的内存随用到的毫秒数增长
+const maxBackfillEntries = 1024
+
+// GenerateAt 生成时间戳为 t 的 ID，用于导入历史数据时让 ID 的时间与原记录的创建时间一致
+// 同一毫秒（使用微秒时间戳时为同一微秒）内的多条记录会依次使用递增的序号，单个毫秒的序号用完时返回错误；
+// t 早于开始时间或超出时间戳的范围时返回错误
+// GenerateAt 为每个用到的毫秒记录已用的序号，与 NextID 相互独立，
+// 因此 t 不应落在 NextID 正在使用的时间范围内，否则可能与 NextID 生成的 ID 重复
+// 记录最多保留 maxBackfillEntries 个毫秒，超出时只保留最晚的一个，此后早于它的时间返回错误而不是生成重复的 ID；
+// 按时间顺序导入时不受影响，乱序导入的范围超过 maxBackfillEntries 个毫秒时需要先排序
+func (s *SnowFlake) GenerateAt(t time.Time) (int64, error) {
+	if s.layout.config.Unsigned {
+		return 0, ErrUnsignedLayout
+	}
+
+	t = t.UTC()
+	if t.Before(s.startTime) {
+		return 0, fmt.Errorf("snowflake: time %s is before start time %s", t, s.startTime)
+	}
+	timestamp := t.UnixNano() / int64(s.layout.unit)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isClosed() {
+		return 0, ErrClosed
+	}
+	sequence, ok := s.backfill[timestamp]
+	if !ok && len(s.backfill) >= maxBackfillEntries {
+		s.trimBackfill()
+	}
+	if timestamp < s.backfillFloor {
+		return 0, fmt.Errorf("snowflake: time %s is before %s, sequences of earlier times are no longer tracked",
+			t, time.Unix(0, s.backfillFloor*int64(s.layout.unit)).UTC())
+	}
+	if sequence > s.layout.sequenceMask {
+		return 0, fmt.Errorf("snowflake: sequence of %s exhausted", t.Truncate(s.layout.unit))
+	}
+
+	id, err := s.compose(timestamp, sequence)
+	if err != nil {
+		return 0, err
+	}
+	if s.backfill == nil {
+		s.backfill = make(map[int64]int64)
+	}
+	s.backfill[timestamp] = sequence + 1
+	if timestamp > s.backfillLatest {
+		s.backfillLatest = timestamp
+	}
+
+	return id, nil
+}
+
+// trimBackfill 丢弃最晚时间戳之外的记录，早于最晚时间戳的时间此后由 GenerateAt 拒绝
+func (s *SnowFlake) trimBackfill() {
+	latest := s.backfill[s.backfillLatest]
+	s.backfill = map[int64]int64{s.backfillLatest: latest}
+	s.backfillFloor = s.backfillLatest
+}
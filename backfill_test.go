@@ -0,0 +1,114 @@
+package snowflake_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestGenerateAt(t *testing.T) {
+	sf := snowflake.NewWith(testStartTime, 1, 2)
+	createdAt := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	seen := make(map[int64]struct{})
+	for i := 0; i < 4096; i++ {
+		id, err := sf.GenerateAt(createdAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := seen[id]; ok {
+			t.Fatalf("duplicate id %d", id)
+		}
+		seen[id] = struct{}{}
+		if got := sf.TimeOf(id); !got.Equal(createdAt) {
+			t.Fatalf("TimeOf = %s, want %s", got, createdAt)
+		}
+	}
+
+	// 同一毫秒的序号用完
+	if _, err := sf.GenerateAt(createdAt); err == nil {
+		t.Error("GenerateAt should fail when the millisecond's sequence is exhausted")
+	}
+	// 其他毫秒不受影响
+	if _, err := sf.GenerateAt(createdAt.Add(time.Millisecond)); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := sf.GenerateAt(testStartTime.Add(-time.Millisecond)); err == nil {
+		t.Error("GenerateAt should reject times before the start time")
+	}
+	if _, err := sf.GenerateAt(testStartTime.Add(time.Duration(1<<41) * time.Millisecond)); !errors.Is(err, snowflake.ErrTimestampOverflow) {
+		t.Errorf("err = %v, want ErrTimestampOverflow", err)
+	}
+}
+
+func TestGenerateAtBoundedMemory(t *testing.T) {
+	sf := snowflake.NewWith(testStartTime, 1, 2)
+	createdAt := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// 按时间顺序导入，记录的毫秒数不随导入的范围增长
+	seen := make(map[int64]struct{})
+	for i := 0; i < 5000; i++ {
+		for j := 0; j < 2; j++ {
+			id, err := sf.GenerateAt(createdAt.Add(time.Duration(i) * time.Millisecond))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := seen[id]; ok {
+				t.Fatalf("duplicate id %d", id)
+			}
+			seen[id] = struct{}{}
+		}
+	}
+	if got := sf.BackfillLen(); got > 1024 {
+		t.Errorf("BackfillLen = %d, want at most 1024", got)
+	}
+
+	// 不再记录的早期时间返回错误，而不是生成重复的 ID
+	if _, err := sf.GenerateAt(createdAt); err == nil {
+		t.Error("GenerateAt with an untracked earlier time succeeded, want error")
+	}
+
+	// 范围内的乱序导入不受影响
+	last := createdAt.Add(4999 * time.Millisecond)
+	for _, at := range []time.Time{last.Add(-time.Millisecond), last.Add(time.Second), last.Add(-time.Millisecond)} {
+		id, err := sf.GenerateAt(at)
+		if err != nil {
+			t.Fatalf("GenerateAt(%s): %v", at, err)
+		}
+		if _, ok := seen[id]; ok {
+			t.Fatalf("duplicate id %d", id)
+		}
+		seen[id] = struct{}{}
+	}
+}
+
+func TestGenerateAtReset(t *testing.T) {
+	sf := snowflake.NewWith(testStartTime, 1, 2)
+	createdAt := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	first, err := sf.GenerateAt(createdAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 2000; i++ {
+		if _, err := sf.GenerateAt(createdAt.Add(time.Duration(i) * time.Millisecond)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sf.Reset()
+	if got := sf.BackfillLen(); got != 0 {
+		t.Errorf("BackfillLen after Reset = %d, want 0", got)
+	}
+	// Reset 后从头开始记录，同一时间重新从序号 0 开始
+	id, err := sf.GenerateAt(createdAt)
+	if err != nil {
+		t.Fatalf("GenerateAt after Reset: %v", err)
+	}
+	if id != first {
+		t.Errorf("GenerateAt after Reset = %d, want %d", id, first)
+	}
+}
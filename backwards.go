@@ -0,0 +1,31 @@
+package snowflake
+
+import "time"
+
+// ClockBackwardsPolicy 时钟回拨超出可容忍范围（SetMaxBackwardTolerance）时的处理方式，回拨不超过一秒时见 leapSecondTolerance
+// 任何一种方式都不会生成重复的 ID，区别在于调用方看到的是错误、阻塞还是时间不准的 ID
+type ClockBackwardsPolicy int
+
+const (
+	// BackwardsError 返回 *ClockBackwardsError，由调用方决定重试还是失败，默认方式
+	BackwardsError ClockBackwardsPolicy = iota
+	// BackwardsPanic 以 *ClockBackwardsError panic，适合时钟回拨即应终止进程的部署；可以通过 WithPanicHandler 接管
+	BackwardsPanic
+	// BackwardsWait 持锁等待时钟追上上次的时间戳后继续生成，回拨期间所有调用都会阻塞（可通过 ctx 取消）
+	BackwardsWait
+	// BackwardsAdvanceLogical 类似 Sonyflake，沿用上次的时间戳继续递增序号，序号用完时在逻辑上推进一个时间单位而不等待时钟；
+	// 回拨期间 ID 中的时间会领先于实际时间，直到时钟追上
+	BackwardsAdvanceLogical
+)
+
+// leapSecondTolerance 闰秒可能造成的最大回拨
+// 闰秒插入时系统时钟可能回跳一秒（或在 smear 期间短暂重复、倒退），这类回拨不是故障，
+// 不超过该值时，BackwardsError 和 BackwardsPanic 也按 BackwardsWait 等待时钟追上，超过时才按设置的方式处理
+const leapSecondTolerance = time.Second
+
+// SetClockBackwardsPolicy 设置时钟回拨超出可容忍范围时的处理方式，默认为 BackwardsError；应在生成 ID 之前设置
+func (s *SnowFlake) SetClockBackwardsPolicy(p ClockBackwardsPolicy) {
+	s.mutex.Lock()
+	s.backwardsPolicy = p
+	s.mutex.Unlock()
+}
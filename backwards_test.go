@@ -0,0 +1,121 @@
+package snowflake_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestBackwardsPanic(t *testing.T) {
+	clock := newFakeClock(testNow, testNow.Add(-5*time.Second), testNow.Add(time.Millisecond))
+	sf := snowflake.NewWithClock(testStartTime, clock, 1, 2)
+	sf.SetClockBackwardsPolicy(snowflake.BackwardsPanic)
+	first := sf.MustNextID()
+
+	func() {
+		defer func() {
+			var backwardsErr *snowflake.ClockBackwardsError
+			if err, _ := recover().(error); !errors.As(err, &backwardsErr) {
+				t.Errorf("recovered %v, want *ClockBackwardsError", err)
+			}
+		}()
+		sf.NextID()
+	}()
+
+	// panic 之后锁已释放，生成器仍可使用
+	if id := sf.MustNextID(); id <= first {
+		t.Errorf("id after panic %d is not greater than %d", id, first)
+	}
+}
+
+func TestWithPanicHandler(t *testing.T) {
+	var handled []error
+	clock := newFakeClock(testNow, testNow, testNow.Add(-5*time.Second), testNow.Add(time.Millisecond))
+	sf, err := snowflake.NewWithOptions(
+		snowflake.WithStartTime(testStartTime),
+		snowflake.WithDataCenterID(1),
+		snowflake.WithWorkerID(2),
+		snowflake.WithClock(clock),
+		snowflake.WithClockBackwardsPolicy(snowflake.BackwardsPanic),
+		snowflake.WithPanicHandler(func(err error) { handled = append(handled, err) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	first := sf.MustNextID()
+
+	// 调用 handler 而不是 panic，NextID 返回同一个错误
+	_, err = sf.NextID()
+	var backwardsErr *snowflake.ClockBackwardsError
+	if !errors.As(err, &backwardsErr) || backwardsErr.Delta != 5000 {
+		t.Fatalf("err = %v, want *ClockBackwardsError with Delta 5000", err)
+	}
+	if len(handled) != 1 || handled[0] != err {
+		t.Errorf("handler got %v, want [%v]", handled, err)
+	}
+
+	if id := sf.MustNextID(); id <= first {
+		t.Errorf("id after the handler %d is not greater than %d", id, first)
+	}
+}
+
+func TestBackwardsWait(t *testing.T) {
+	clock := newFakeClock(testNow, testNow.Add(-5*time.Millisecond), testNow.Add(-3*time.Millisecond), testNow)
+	sf, err := snowflake.NewWithOptions(
+		snowflake.WithStartTime(testStartTime),
+		snowflake.WithDataCenterID(1),
+		snowflake.WithWorkerID(2),
+		snowflake.WithClock(clock),
+		snowflake.WithClockBackwardsPolicy(snowflake.BackwardsWait),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	first := sf.MustNextID()
+
+	second, err := sf.NextID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second <= first {
+		t.Errorf("second id %d is not greater than first %d", second, first)
+	}
+
+	// 等待时钟追上时可以通过 ctx 取消
+	sf = snowflake.NewWithClock(testStartTime, newFakeClock(testNow, testNow.Add(-time.Hour)), 1, 2)
+	sf.SetClockBackwardsPolicy(snowflake.BackwardsWait)
+	sf.MustNextID()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := sf.NextIDContext(ctx); err != context.Canceled {
+		t.Errorf("NextIDContext() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestBackwardsAdvanceLogical(t *testing.T) {
+	// 时钟回拨一小时后一直停在那里
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(testNow, testNow.Add(-time.Hour)), 1, 2)
+	sf.SetClockBackwardsPolicy(snowflake.BackwardsAdvanceLogical)
+
+	prev := sf.MustNextID()
+	for i := 0; i < 5000; i++ {
+		id, err := sf.NextID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= prev {
+			t.Fatalf("id %d is not greater than previous %d", id, prev)
+		}
+		prev = id
+	}
+
+	// 用完第一毫秒的序号后逻辑上推进到下一毫秒
+	if got, want := sf.TimeOf(prev), testNow.Add(time.Millisecond); !got.Equal(want) {
+		t.Errorf("TimeOf(last id) = %s, want %s", got, want)
+	}
+}
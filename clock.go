@@ -0,0 +1,16 @@
+package snowflake
+
+import "time"
+
+// Clock 时间源，默认使用系统时间，测试时可以替换为可控的实现
+// 实现需要是并发安全的
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock 系统时间
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,45 @@
+// Package clocktest 提供手动控制的 snowflake.Clock，用于在测试中精确地驱动生成器经过毫秒切换、序号用完和时钟回拨
+package clocktest
+
+import (
+	"sync"
+	"time"
+)
+
+// ManualClock 只有调用 Set、Advance 或 SetBackward 时才会变化的时钟，实现了 snowflake.Clock，并发安全
+// 生成器在序号用完或等待时钟追上时会不停地读取时钟，这时需要在另一个 goroutine 中调用 Advance
+type ManualClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+// New 创建当前时间为 now 的 ManualClock
+func New(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+// Now 实现 snowflake.Clock
+func (c *ManualClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// Set 将时钟设置为 t
+func (c *ManualClock) Set(t time.Time) {
+	c.mutex.Lock()
+	c.now = t
+	c.mutex.Unlock()
+}
+
+// Advance 将时钟向前拨 d
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	c.now = c.now.Add(d)
+	c.mutex.Unlock()
+}
+
+// SetBackward 将时钟往回拨 d，模拟时钟回拨
+func (c *ManualClock) SetBackward(d time.Duration) {
+	c.Advance(-d)
+}
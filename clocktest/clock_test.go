@@ -0,0 +1,120 @@
+package clocktest_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+	"github.com/polaris1119/snowflake/clocktest"
+)
+
+var (
+	testStartTime = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	testNow       = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// blockTimeout 判断 NextID 是否阻塞时等待的时长
+const blockTimeout = 50 * time.Millisecond
+
+func TestManualClock(t *testing.T) {
+	c := clocktest.New(testNow)
+	c.Advance(time.Second)
+	c.SetBackward(300 * time.Millisecond)
+	if want := testNow.Add(700 * time.Millisecond); !c.Now().Equal(want) {
+		t.Errorf("Now() = %s, want %s", c.Now(), want)
+	}
+	c.Set(testStartTime)
+	if !c.Now().Equal(testStartTime) {
+		t.Errorf("Now() = %s, want %s", c.Now(), testStartTime)
+	}
+}
+
+// nextIDAsync 在另一个 goroutine 中调用 NextID，等待时钟前进时由调用方 Advance
+func nextIDAsync(sf *snowflake.SnowFlake) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		_, err := sf.NextID()
+		done <- err
+	}()
+	return done
+}
+
+func TestNextIDRollover(t *testing.T) {
+	c := clocktest.New(testNow)
+	sf := snowflake.NewWithClock(testStartTime, c, 1, 2)
+
+	sf.MustNextID()
+	if p := sf.Decompose(sf.MustNextID()); p.Sequence != 1 {
+		t.Errorf("second id in the same millisecond has sequence %d, want 1", p.Sequence)
+	}
+
+	// 进入下一毫秒，序号重置
+	c.Advance(time.Millisecond)
+	p := sf.Decompose(sf.MustNextID())
+	if p.Sequence != 0 || !p.Time.Equal(testNow.Add(time.Millisecond)) {
+		t.Errorf("id after rollover = %s, want sequence 0 at %s", p, testNow.Add(time.Millisecond))
+	}
+}
+
+func TestNextIDSequenceExhausted(t *testing.T) {
+	c := clocktest.New(testNow)
+	sf := snowflake.NewWithClock(testStartTime, c, 1, 2)
+	for i := 0; i < sf.MaxIDsPerMillisecond(); i++ {
+		sf.MustNextID()
+	}
+
+	// 序号用完后阻塞到时钟进入下一毫秒
+	done := nextIDAsync(sf)
+	select {
+	case err := <-done:
+		t.Fatalf("NextID returned %v before the clock advanced", err)
+	case <-time.After(blockTimeout):
+	}
+	c.Advance(time.Millisecond)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if got := sf.Stats().SequenceExhaustedWaits; got != 1 {
+		t.Errorf("SequenceExhaustedWaits = %d, want 1", got)
+	}
+}
+
+func TestNextIDBackward(t *testing.T) {
+	c := clocktest.New(testNow)
+	sf := snowflake.NewWithClock(testStartTime, c, 1, 2)
+	last := sf.MustNextID()
+
+	// 回拨超过一秒时报错
+	c.SetBackward(5 * time.Second)
+	_, err := sf.NextID()
+	var backwardsErr *snowflake.ClockBackwardsError
+	if !errors.As(err, &backwardsErr) || backwardsErr.Delta != 5000 {
+		t.Fatalf("err = %v, want *ClockBackwardsError with Delta 5000", err)
+	}
+
+	// 可容忍范围内的回拨沿用上次的时间戳
+	c.Set(testNow.Add(-10 * time.Millisecond))
+	sf.SetMaxBackwardTolerance(20 * time.Millisecond)
+	id, err := sf.NextID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id <= last || !sf.TimeOf(id).Equal(testNow) {
+		t.Errorf("id within tolerance = %s, want after %d at %s", sf.Decompose(id), last, testNow)
+	}
+	sf.SetMaxBackwardTolerance(0)
+
+	// 不超过一秒的回拨（如闰秒）等待时钟追上
+	c.Set(testNow.Add(-500 * time.Millisecond))
+	done := nextIDAsync(sf)
+	select {
+	case err := <-done:
+		t.Fatalf("NextID returned %v before the clock caught up", err)
+	case <-time.After(blockTimeout):
+	}
+	c.Set(testNow.Add(time.Millisecond))
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,53 @@
+package snowflake
+
+// Compare 按默认位分配比较两个 ID 的时间戳部分，a 早于 b 返回 -1，晚于返回 1，同一毫秒返回 0
+// 不比较节点和序号，不同节点同一毫秒生成的 ID 视为相等；自定义位分配时使用 Config.Compare
+func Compare(a, b int64) int {
+	return DefaultConfig.Compare(a, b)
+}
+
+// Before 按默认位分配判断 a 的时间戳是否早于 b
+func Before(a, b int64) bool {
+	return Compare(a, b) < 0
+}
+
+// After 按默认位分配判断 a 的时间戳是否晚于 b
+func After(a, b int64) bool {
+	return Compare(a, b) > 0
+}
+
+// Compare 按 c 的位分配比较两个 ID 的时间戳部分，结果同包级别的 Compare
+func (c Config) Compare(a, b int64) int {
+	shift := c.TagBits + c.SequenceBits + c.WorkerBits + c.DataCenterBits
+	// 使用无符号右移，uint64 位分配的 ID 同样适用
+	ta, tb := uint64(a)>>shift, uint64(b)>>shift
+	switch {
+	case ta < tb:
+		return -1
+	case ta > tb:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Before 按 c 的位分配判断 a 的时间戳是否早于 b
+func (c Config) Before(a, b int64) bool {
+	return c.Compare(a, b) < 0
+}
+
+// After 按 c 的位分配判断 a 的时间戳是否晚于 b
+func (c Config) After(a, b int64) bool {
+	return c.Compare(a, b) > 0
+}
+
+// IsMonotonic 判断 ids 是否严格递增，空切片和只有一个元素时返回 true
+// 只比较数值大小：同一生成器生成的 ID 数值顺序就是生成顺序；来自多个节点的 ID 只按时间有序，通常不是严格递增的
+func IsMonotonic(ids []int64) bool {
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			return false
+		}
+	}
+	return true
+}
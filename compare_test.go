@@ -0,0 +1,90 @@
+package snowflake_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestCompare(t *testing.T) {
+	later := testNow.Add(time.Millisecond)
+	node1 := snowflake.NewWithClock(testStartTime, newFakeClock(testNow, testNow, later), 1, 1)
+	node2 := snowflake.NewWithClock(testStartTime, newFakeClock(testNow, later), 31, 31)
+
+	a1, a2, a3 := node1.MustNextID(), node1.MustNextID(), node1.MustNextID()
+	b1, b2 := node2.MustNextID(), node2.MustNextID()
+
+	tests := []struct {
+		name string
+		a, b int64
+		want int
+	}{
+		{"same node same millisecond", a1, a2, 0},
+		{"same node later millisecond", a3, a2, 1},
+		{"cross node same millisecond", a1, b1, 0},
+		{"cross node earlier millisecond", b1, a3, -1},
+		{"cross node later millisecond", b2, a1, 1},
+	}
+	for _, tt := range tests {
+		if got := snowflake.Compare(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: Compare(%d, %d) = %d, want %d", tt.name, tt.a, tt.b, got, tt.want)
+		}
+		if got := snowflake.Before(tt.a, tt.b); got != (tt.want < 0) {
+			t.Errorf("%s: Before = %v, want %v", tt.name, got, tt.want < 0)
+		}
+		if got := snowflake.After(tt.a, tt.b); got != (tt.want > 0) {
+			t.Errorf("%s: After = %v, want %v", tt.name, got, tt.want > 0)
+		}
+	}
+
+	// 原始整数比较会受节点位影响
+	if b1 < a1 {
+		t.Fatalf("test setup: b1 %d should be numerically greater than a1 %d", b1, a1)
+	}
+}
+
+func TestConfigCompare(t *testing.T) {
+	cfg := snowflake.Config{TimestampBits: 41, DataCenterBits: 3, WorkerBits: 7, SequenceBits: 12}
+	later := testNow.Add(time.Millisecond)
+
+	sf, err := snowflake.NewWithOptions(
+		snowflake.WithConfig(cfg),
+		snowflake.WithStartTime(testStartTime),
+		// NewWithOptions 校验开始时间时会读取一次时钟
+		snowflake.WithClock(newFakeClock(testNow, testNow, later)),
+		snowflake.WithDataCenterID(7),
+		snowflake.WithWorkerID(127),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	a, b := sf.MustNextID(), sf.MustNextID()
+
+	if !cfg.Before(a, b) || cfg.After(a, b) || cfg.Compare(b, a) != 1 {
+		t.Errorf("Config.Compare(%d, %d) ordering is wrong", a, b)
+	}
+}
+
+func TestIsMonotonic(t *testing.T) {
+	sf := snowflake.NewWith(testStartTime, 1, 2)
+	ids, err := sf.NextIDs(5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snowflake.IsMonotonic(ids) {
+		t.Error("IsMonotonic(NextIDs(5000)) = false, want true")
+	}
+
+	for _, ids := range [][]int64{nil, {1}, {1, 2, 10}} {
+		if !snowflake.IsMonotonic(ids) {
+			t.Errorf("IsMonotonic(%v) = false, want true", ids)
+		}
+	}
+	for _, ids := range [][]int64{{1, 1}, {1, 3, 2}, {2, 1}} {
+		if snowflake.IsMonotonic(ids) {
+			t.Errorf("IsMonotonic(%v) = true, want false", ids)
+		}
+	}
+}
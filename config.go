@@ -0,0 +1,195 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Config ID 的位分配，各部分之和必须为 63（最高位是符号位，固定为 0）
+// Unsigned 为 true 时各部分之和必须为 64，连同符号位一起使用，只能通过 NextUID 生成 uint64 的 ID
+type Config struct {
+	TimestampBits  uint
+	DataCenterBits uint
+	WorkerBits     uint
+	SequenceBits   uint
+
+	// TagBits 在序号之后保留的标签位数，可以为 0（默认，没有标签），最多 8 位，见 NextIDTagged
+	// 标签位于最低位，同一毫秒内的 ID 仍按序号严格递增
+	TagBits uint
+
+	Unsigned bool
+
+	// Unit 时间戳的单位，默认为毫秒
+	Unit TimeUnit
+}
+
+// TimeUnit 时间戳的单位
+type TimeUnit int
+
+const (
+	// Millisecond 毫秒，默认的时间单位
+	Millisecond TimeUnit = iota
+	// Microsecond 微秒，每个节点每微秒可以生成 2^SequenceBits 个 ID，是毫秒的 1000 倍，
+	// 但同样的位数只能使用毫秒的 1/1000 的时间：41 位时间戳约 25 天，约 51 位才能使用 69 年
+	Microsecond
+)
+
+// duration 返回时间单位对应的 time.Duration
+func (u TimeUnit) duration() time.Duration {
+	if u == Microsecond {
+		return time.Microsecond
+	}
+	return time.Millisecond
+}
+
+// DefaultConfig 默认的位分配：41 位时间戳、5 位数据中心 ID、5 位工作机器 ID、12 位序号
+var DefaultConfig = Config{
+	TimestampBits:  41,
+	DataCenterBits: 5,
+	WorkerBits:     5,
+	SequenceBits:   12,
+}
+
+// DefaultUnsignedConfig 默认的 uint64 位分配：42 位时间戳，其余同 DefaultConfig，时间戳可以使用约 139 年
+var DefaultUnsignedConfig = Config{
+	TimestampBits:  42,
+	DataCenterBits: 5,
+	WorkerBits:     5,
+	SequenceBits:   12,
+	Unsigned:       true,
+}
+
+// totalBits 各部分之和应有的位数
+func (c Config) totalBits() uint {
+	if c.Unsigned {
+		return 64
+	}
+	return 63
+}
+
+// Validate 校验位分配是否合法
+func (c Config) Validate() error {
+	if c.TimestampBits == 0 || c.DataCenterBits == 0 || c.WorkerBits == 0 || c.SequenceBits == 0 {
+		return errors.New("snowflake: every field of Config must be greater than 0")
+	}
+	// dataCenterID 和 workerID 是 uint8；序号以 int64 存储，位数只受总位数限制
+	if c.DataCenterBits > 8 || c.WorkerBits > 8 {
+		return fmt.Errorf("snowflake: DataCenterBits(%d) and WorkerBits(%d) must not exceed 8", c.DataCenterBits, c.WorkerBits)
+	}
+	// 标签是 uint8
+	if c.TagBits > 8 {
+		return fmt.Errorf("snowflake: TagBits(%d) must not exceed 8", c.TagBits)
+	}
+	if c.Unit != Millisecond && c.Unit != Microsecond {
+		return fmt.Errorf("snowflake: unknown Unit %d", c.Unit)
+	}
+	if total := c.TimestampBits + c.DataCenterBits + c.WorkerBits + c.SequenceBits + c.TagBits; total != c.totalBits() {
+		return fmt.Errorf("snowflake: total bits of Config is %d, want %d", total, c.totalBits())
+	}
+	return nil
+}
+
+// layout 根据 Config 计算出的掩码和左移位数
+type layout struct {
+	config Config
+
+	tagMask        int64
+	sequenceMask   int64
+	workerMask     int64
+	dataCenterMask int64
+
+	sequenceLeftShift   uint
+	workerLeftShift     uint
+	dataCenterLeftShift uint
+	timestampLeftShift  uint
+
+	// 时间戳差值的最大值
+	maxElapsed int64
+
+	// 时间戳的单位
+	unit time.Duration
+}
+
+var defaultLayout = newLayout(DefaultConfig)
+
+func newLayout(c Config) layout {
+	return layout{
+		config: c,
+
+		tagMask:        1<<c.TagBits - 1,
+		sequenceMask:   1<<c.SequenceBits - 1,
+		workerMask:     1<<c.WorkerBits - 1,
+		dataCenterMask: 1<<c.DataCenterBits - 1,
+
+		sequenceLeftShift:   c.TagBits,
+		workerLeftShift:     c.TagBits + c.SequenceBits,
+		dataCenterLeftShift: c.TagBits + c.SequenceBits + c.WorkerBits,
+		timestampLeftShift:  c.TagBits + c.SequenceBits + c.WorkerBits + c.DataCenterBits,
+
+		maxElapsed: 1<<c.TimestampBits - 1,
+		unit:       c.Unit.duration(),
+	}
+}
+
+// 以下函数返回位分配对应的左移位数和掩码，供外部服务自行解码 ID，layout 需要是合法的位分配（见 Config.Validate）
+// 解码时先将 ID 右移对应的位数，再与掩码按位与；时间戳为 ID 右移 TimestampShift 位（uint64 位分配需按无符号数右移）
+
+// TimestampShift 时间戳的左移位数
+func TimestampShift(layout Config) uint {
+	return newLayout(layout).timestampLeftShift
+}
+
+// DataCenterShift dataCenterID 的左移位数
+func DataCenterShift(layout Config) uint {
+	return newLayout(layout).dataCenterLeftShift
+}
+
+// WorkerShift workerID 的左移位数
+func WorkerShift(layout Config) uint {
+	return newLayout(layout).workerLeftShift
+}
+
+// SequenceShift 序号的左移位数，没有标签位时为 0
+func SequenceShift(layout Config) uint {
+	return newLayout(layout).sequenceLeftShift
+}
+
+// SequenceMask 序号右移 SequenceShift 位后的掩码
+func SequenceMask(layout Config) int64 {
+	return newLayout(layout).sequenceMask
+}
+
+// TagMask 标签的掩码，标签位于最低位，无需移位；没有标签位时为 0
+func TagMask(layout Config) int64 {
+	return newLayout(layout).tagMask
+}
+
+// DataCenterMask dataCenterID 右移 DataCenterShift 位后的掩码
+func DataCenterMask(layout Config) int64 {
+	return newLayout(layout).dataCenterMask
+}
+
+// WorkerMask workerID 右移 WorkerShift 位后的掩码
+func WorkerMask(layout Config) int64 {
+	return newLayout(layout).workerMask
+}
+
+// checkNode 校验 dataCenterID 和 workerID 是否在位分配的范围内
+func (l layout) checkNode(dataCenterID, workerID uint8) error {
+	if int64(dataCenterID) > l.dataCenterMask {
+		return fmt.Errorf("%w: dataCenterID %d not in [0, %d]", ErrNodeIDOutOfRange, dataCenterID, l.dataCenterMask)
+	}
+	if int64(workerID) > l.workerMask {
+		return fmt.Errorf("%w: workerID %d not in [0, %d]", ErrNodeIDOutOfRange, workerID, l.workerMask)
+	}
+	return nil
+}
+
+// NewWithConfig 使用自定义位分配创建 SnowFlake，startTime 和 ids 的含义同 NewWith
+func NewWithConfig(cfg Config, startTime time.Time, ids ...uint8) (*SnowFlake, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return newWith(newLayout(cfg), startTime, ids...), nil
+}
@@ -0,0 +1,282 @@
+package snowflake_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		cfg     snowflake.Config
+		wantErr bool
+	}{
+		{snowflake.DefaultConfig, false},
+		{snowflake.Config{TimestampBits: 41, DataCenterBits: 3, WorkerBits: 7, SequenceBits: 12}, false},
+		{snowflake.Config{TimestampBits: 43, DataCenterBits: 5, WorkerBits: 5, SequenceBits: 10}, false},
+		{snowflake.Config{TimestampBits: 0, DataCenterBits: 5, WorkerBits: 5, SequenceBits: 53}, true},
+		{snowflake.Config{TimestampBits: 41, DataCenterBits: 5, WorkerBits: 5, SequenceBits: 13}, true},
+		{snowflake.Config{TimestampBits: 40, DataCenterBits: 5, WorkerBits: 5, SequenceBits: 12}, true},
+		{snowflake.Config{TimestampBits: 37, DataCenterBits: 5, WorkerBits: 9, SequenceBits: 12}, true},
+		// 序号以 int64 存储，超过 15 位也合法
+		{snowflake.Config{TimestampBits: 37, DataCenterBits: 5, WorkerBits: 5, SequenceBits: 16}, false},
+		{snowflake.DefaultUnsignedConfig, false},
+		{snowflake.Config{TimestampBits: 41, DataCenterBits: 5, WorkerBits: 5, SequenceBits: 12, Unsigned: true}, true},
+	}
+
+	for _, tt := range tests {
+		err := tt.cfg.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%+v.Validate() error = %v, wantErr %v", tt.cfg, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNewWithConfig(t *testing.T) {
+	cfg := snowflake.Config{TimestampBits: 41, DataCenterBits: 3, WorkerBits: 7, SequenceBits: 12}
+	startTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	sf, err := snowflake.NewWithConfig(cfg, startTime, 5, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sf.DataCenterID() != 5 || sf.WorkerID() != 100 {
+		t.Errorf("DataCenterID, WorkerID = %d, %d, want 5, 100", sf.DataCenterID(), sf.WorkerID())
+	}
+
+	if got := sf.Layout(); got != cfg {
+		t.Errorf("Layout() = %+v, want %+v", got, cfg)
+	}
+	if got := sf.Epoch(); !got.Equal(startTime) {
+		t.Errorf("Epoch() = %s, want %s", got, startTime)
+	}
+
+	id := sf.MustNextID()
+	if got := id >> 12 & (1<<7 - 1); got != 100 {
+		t.Errorf("worker bits = %d, want 100", got)
+	}
+	if got := id >> 19 & (1<<3 - 1); got != 5 {
+		t.Errorf("data center bits = %d, want 5", got)
+	}
+	if got := sf.TimeOf(id); time.Since(got) > time.Second {
+		t.Errorf("TimeOf = %s, want close to now", got)
+	}
+
+	if got := snowflake.NewWith(startTime).Layout(); got != snowflake.DefaultConfig {
+		t.Errorf("NewWith().Layout() = %+v, want DefaultConfig", got)
+	}
+
+	if _, err := snowflake.NewWithConfig(snowflake.Config{}, startTime); err == nil {
+		t.Error("NewWithConfig with zero Config should return an error")
+	}
+}
+
+func TestNextUID(t *testing.T) {
+	startTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	// 超出 41 位时间戳的范围，只有 uint64 位分配才能表示
+	now := startTime.Add(time.Duration(1<<41+5) * time.Millisecond)
+
+	sf, err := snowflake.NewWithOptions(
+		snowflake.WithConfig(snowflake.DefaultUnsignedConfig),
+		snowflake.WithStartTime(startTime),
+		snowflake.WithClock(newFakeClock(now)),
+		snowflake.WithDataCenterID(3),
+		snowflake.WithWorkerID(7),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+
+	uid, err := sf.NextUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uid>>63 != 1 {
+		t.Errorf("NextUID() = %#x, want the top bit set", uid)
+	}
+	if got := uid >> 22; got != 1<<41+5 {
+		t.Errorf("timestamp bits = %d, want %d", got, uint64(1<<41+5))
+	}
+	if got := uid >> 17 & 0x1F; got != 3 {
+		t.Errorf("data center bits = %d, want 3", got)
+	}
+	if got := sf.TimeOf(int64(uid)); !got.Equal(now) {
+		t.Errorf("TimeOf = %s, want %s", got, now)
+	}
+
+	if _, err := sf.NextID(); err != snowflake.ErrUnsignedLayout {
+		t.Errorf("NextID() error = %v, want ErrUnsignedLayout", err)
+	}
+	if _, err := sf.NextIDs(2); err != snowflake.ErrUnsignedLayout {
+		t.Errorf("NextIDs() error = %v, want ErrUnsignedLayout", err)
+	}
+}
+
+func TestNextUIDSignedLayout(t *testing.T) {
+	sf := snowflake.NewWith(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), 1, 1)
+
+	a := sf.MustNextID()
+	b := sf.MustNextUID()
+	if b <= uint64(a) {
+		t.Errorf("MustNextUID() = %d, want greater than %d", b, a)
+	}
+}
+
+func TestMaxIDs(t *testing.T) {
+	startTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	sf := snowflake.NewWith(startTime, 1, 1)
+	if got := sf.MaxIDsPerMillisecond(); got != 4096 {
+		t.Errorf("MaxIDsPerMillisecond() = %d, want 4096", got)
+	}
+	if got := sf.MaxIDsPerSecond(); got != 4096000 {
+		t.Errorf("MaxIDsPerSecond() = %d, want 4096000", got)
+	}
+
+	sf, err := snowflake.NewWithConfig(snowflake.Config{TimestampBits: 43, DataCenterBits: 5, WorkerBits: 5, SequenceBits: 10}, startTime, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sf.MaxIDsPerMillisecond(); got != 1024 {
+		t.Errorf("MaxIDsPerMillisecond() with 10 sequence bits = %d, want 1024", got)
+	}
+
+	// 微秒时间戳下每秒的 ID 数超过 32 位 int 的范围
+	sf, err = snowflake.NewWithConfig(snowflake.Config{TimestampBits: 41, DataCenterBits: 5, WorkerBits: 5, SequenceBits: 12, Unit: snowflake.Microsecond}, startTime, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sf.MaxIDsPerSecond(); got != 4096000000 {
+		t.Errorf("MaxIDsPerSecond() with microsecond unit = %d, want 4096000000", got)
+	}
+}
+
+func TestMicrosecondUnit(t *testing.T) {
+	cfg := snowflake.Config{TimestampBits: 51, DataCenterBits: 3, WorkerBits: 3, SequenceBits: 6, Unit: snowflake.Microsecond}
+	startTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2021, 1, 1, 0, 0, 0, 123456000, time.UTC)
+
+	// 64 个 ID 用完当前微秒的序号，第 65 个等到下一微秒
+	times := append([]time.Time{now}, repeatTime(now, 65)...)
+	times = append(times, now.Add(time.Microsecond))
+	sf, err := snowflake.NewWithOptions(
+		snowflake.WithConfig(cfg),
+		snowflake.WithStartTime(startTime),
+		snowflake.WithClock(newFakeClock(times...)),
+		snowflake.WithDataCenterID(1),
+		snowflake.WithWorkerID(2),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+
+	if got := sf.MaxIDsPerMillisecond(); got != 64000 {
+		t.Errorf("MaxIDsPerMillisecond() = %d, want 64000", got)
+	}
+	if got := sf.MaxIDsPerSecond(); got != 64000000 {
+		t.Errorf("MaxIDsPerSecond() = %d, want 64000000", got)
+	}
+
+	first := sf.MustNextID()
+	if got := sf.TimeOf(first); !got.Equal(now) {
+		t.Errorf("TimeOf = %s, want %s", got, now)
+	}
+	if got, want := first>>12, now.Sub(startTime).Microseconds(); got != want {
+		t.Errorf("timestamp bits = %d, want %d microseconds", got, want)
+	}
+
+	var last int64
+	for i := 0; i < 64; i++ {
+		last = sf.MustNextID()
+	}
+	if got, want := sf.TimeOf(last), now.Add(time.Microsecond); !got.Equal(want) {
+		t.Errorf("TimeOf(65th id) = %s, want %s", got, want)
+	}
+
+	if _, err := snowflake.NewWithConfig(snowflake.Config{TimestampBits: 41, DataCenterBits: 5, WorkerBits: 5, SequenceBits: 12, Unit: 5}, startTime); err == nil {
+		t.Error("unknown Unit should be rejected")
+	}
+}
+
+func TestWideSequence(t *testing.T) {
+	startTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, cfg := range []snowflake.Config{
+		{TimestampBits: 41, DataCenterBits: 5, WorkerBits: 4, SequenceBits: 13},
+		{TimestampBits: 40, DataCenterBits: 4, WorkerBits: 3, SequenceBits: 16},
+	} {
+		perMs := 1 << cfg.SequenceBits
+		// 同一毫秒内用完全部序号，超过 int16 的范围也不溢出
+		times := append([]time.Time{now}, repeatTime(now, perMs)...)
+		times = append(times, now.Add(time.Millisecond))
+		sf, err := snowflake.NewWithOptions(
+			snowflake.WithConfig(cfg),
+			snowflake.WithStartTime(startTime),
+			snowflake.WithClock(newFakeClock(times...)),
+			snowflake.WithDataCenterID(1),
+			snowflake.WithWorkerID(2),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var prev int64
+		for i := 0; i < perMs; i++ {
+			id := sf.MustNextID()
+			if id <= prev {
+				t.Fatalf("%d sequence bits: id %d is not greater than previous %d", cfg.SequenceBits, id, prev)
+			}
+			prev = id
+		}
+		p := sf.Decompose(prev)
+		if p.Sequence != int64(perMs-1) || !p.Time.Equal(now) || p.DataCenterID != 1 || p.WorkerID != 2 {
+			t.Errorf("%d sequence bits: Decompose(last id) = %+v, want sequence %d at %s", cfg.SequenceBits, p, perMs-1, now)
+		}
+
+		// 下一个 ID 进入下一毫秒，序号回到 0
+		if p := sf.Decompose(sf.MustNextID()); p.Sequence != 0 || !p.Time.Equal(now.Add(time.Millisecond)) {
+			t.Errorf("%d sequence bits: Decompose(next id) = %+v, want sequence 0 in the next millisecond", cfg.SequenceBits, p)
+		}
+		sf.Close()
+	}
+}
+
+func TestShiftsAndMasks(t *testing.T) {
+	if got := snowflake.TimestampShift(snowflake.DefaultConfig); got != 22 {
+		t.Errorf("TimestampShift = %d, want 22", got)
+	}
+	if got := snowflake.DataCenterShift(snowflake.DefaultConfig); got != 17 {
+		t.Errorf("DataCenterShift = %d, want 17", got)
+	}
+	if got := snowflake.WorkerShift(snowflake.DefaultConfig); got != 12 {
+		t.Errorf("WorkerShift = %d, want 12", got)
+	}
+	if got := snowflake.SequenceMask(snowflake.DefaultConfig); got != 0xFFF {
+		t.Errorf("SequenceMask = %#x, want 0xfff", got)
+	}
+
+	// 按导出的移位数和掩码自行解码，结果与 Decompose 一致
+	cfg := snowflake.Config{TimestampBits: 39, DataCenterBits: 3, WorkerBits: 7, SequenceBits: 14}
+	sf, err := snowflake.NewWithConfig(cfg, testStartTime, 5, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := sf.MustNextID()
+	want := sf.Decompose(id)
+	if got := id >> snowflake.TimestampShift(cfg); got != want.ElapsedMs {
+		t.Errorf("elapsed = %d, want %d", got, want.ElapsedMs)
+	}
+	if got := id >> snowflake.DataCenterShift(cfg) & snowflake.DataCenterMask(cfg); got != 5 {
+		t.Errorf("dataCenterID = %d, want 5", got)
+	}
+	if got := id >> snowflake.WorkerShift(cfg) & snowflake.WorkerMask(cfg); got != 100 {
+		t.Errorf("workerID = %d, want 100", got)
+	}
+	if got := id & snowflake.SequenceMask(cfg); got != want.Sequence {
+		t.Errorf("sequence = %d, want %d", got, want.Sequence)
+	}
+}
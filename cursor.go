@@ -0,0 +1,31 @@
+package snowflake
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Cursor 将 ID 编码为分页游标，形如 2024-01-02T03:04:05.678Z_1Bf3kQz7a9c：
+// 前半部分是 ID 的生成时间（RFC3339），便于在日志中查看，后半部分是 ID 的 base62 编码
+// 时间按本 SnowFlake 的开始时间和位分配计算，游标需要用同样配置的生成器的 ParseCursor 解析
+func (s *SnowFlake) Cursor(id int64) string {
+	return formatTime(s.TimeOf(id)) + "_" + EncodeBase62(id)
+}
+
+// ParseCursor 解析 Cursor 生成的游标，格式不对、ID 为负数或时间与 ID 不一致（被篡改）时返回错误
+func (s *SnowFlake) ParseCursor(c string) (int64, error) {
+	i := strings.LastIndexByte(c, '_')
+	if i < 0 {
+		return 0, fmt.Errorf("snowflake: invalid cursor %q", c)
+	}
+
+	id, err := DecodeBase62(c[i+1:])
+	if err != nil {
+		return 0, fmt.Errorf("snowflake: invalid cursor %q: %w", c, err)
+	}
+	if id < 0 || c[:i] != formatTime(s.TimeOf(id)) {
+		return 0, fmt.Errorf("snowflake: invalid cursor %q", c)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,46 @@
+package snowflake_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestCursor(t *testing.T) {
+	now := testStartTime.Add(1234*time.Hour + 567*time.Millisecond)
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(now), 1, 2)
+	id := sf.MustNextID()
+
+	c := sf.Cursor(id)
+	want := now.UTC().Format("2006-01-02T15:04:05.000Z07:00") + "_" + snowflake.EncodeBase62(id)
+	if c != want {
+		t.Fatalf("Cursor(%d) = %q, want %q", id, c, want)
+	}
+
+	got, err := sf.ParseCursor(c)
+	if err != nil {
+		t.Fatalf("ParseCursor(%q) error: %v", c, err)
+	}
+	if got != id {
+		t.Errorf("ParseCursor(Cursor(%d)) = %d", id, got)
+	}
+
+	// 时间部分与 ID 不一致、ID 部分被改动、格式错误都应该报错
+	other := sf.Cursor(id + 1<<22)
+	tampered := []string{
+		"",
+		"_",
+		snowflake.EncodeBase62(id),
+		c[:strings.IndexByte(c, '_')] + other[strings.IndexByte(other, '_'):],
+		c + "!",
+		c[:strings.IndexByte(c, '_')] + "_" + snowflake.EncodeBase62(-id),
+		"x" + c,
+	}
+	for _, c := range tampered {
+		if _, err := sf.ParseCursor(c); err == nil {
+			t.Errorf("ParseCursor(%q) should return an error", c)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package snowflake
+
+import "sync"
+
+var (
+	defaultMutex sync.RWMutex
+	// defaultSF 包级别的默认 SnowFlake，第一次使用时通过 New() 创建
+	defaultSF *SnowFlake
+)
+
+// Default 返回包级别的默认 SnowFlake，未通过 SetDefault 设置时使用 New() 创建
+func Default() *SnowFlake {
+	defaultMutex.RLock()
+	sf := defaultSF
+	defaultMutex.RUnlock()
+	if sf != nil {
+		return sf
+	}
+
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	if defaultSF == nil {
+		defaultSF = New()
+	}
+	return defaultSF
+}
+
+// SetDefault 替换包级别的默认 SnowFlake，一般在程序启动时调用一次
+// sf 为 nil 时，下次使用时重新通过 New() 创建
+func SetDefault(sf *SnowFlake) {
+	defaultMutex.Lock()
+	defaultSF = sf
+	defaultMutex.Unlock()
+}
+
+// NextID 使用默认 SnowFlake 获取一个 ID
+func NextID() (int64, error) {
+	return Default().NextID()
+}
+
+// MustNextID 使用默认 SnowFlake 获取一个 ID，出错时 panic
+func MustNextID() int64 {
+	return Default().MustNextID()
+}
@@ -0,0 +1,28 @@
+package snowflake_test
+
+import (
+	"testing"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestDefault(t *testing.T) {
+	defer snowflake.SetDefault(nil)
+
+	if snowflake.Default() != snowflake.Default() {
+		t.Fatal("Default should return the same generator")
+	}
+	first := snowflake.MustNextID()
+	if second := snowflake.MustNextID(); second <= first {
+		t.Errorf("second id %d is not greater than first %d", second, first)
+	}
+
+	sf := snowflake.NewWith(testStartTime, 7, 9)
+	snowflake.SetDefault(sf)
+	if snowflake.Default() != sf {
+		t.Fatal("Default should return the generator set by SetDefault")
+	}
+	if _, dataCenterID, workerID, _ := snowflake.ParseID(snowflake.MustNextID(), testStartTime); dataCenterID != 7 || workerID != 9 {
+		t.Errorf("dataCenterID, workerID = %d, %d, want 7, 9", dataCenterID, workerID)
+	}
+}
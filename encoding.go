@@ -0,0 +1,221 @@
+package snowflake
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// base62Index 字符到 base62 值的映射，非法字符为 0xFF
+var base62Index = func() [256]byte {
+	var index [256]byte
+	for i := range index {
+		index[i] = 0xFF
+	}
+	for i := 0; i < len(base62Alphabet); i++ {
+		index[base62Alphabet[i]] = byte(i)
+	}
+	return index
+}()
+
+// sortableAlphabet Crockford Base32 字母表，字符的 ASCII 顺序与数值顺序一致
+const sortableAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// sortableWidth 64 位需要 13 个 Base32 字符
+const sortableWidth = 13
+
+// sortableIndex 字符到 Base32 值的映射，不区分大小写，非法字符为 0xFF
+var sortableIndex = func() [256]byte {
+	var index [256]byte
+	for i := range index {
+		index[i] = 0xFF
+	}
+	for i := 0; i < len(sortableAlphabet); i++ {
+		c := sortableAlphabet[i]
+		index[c] = byte(i)
+		if 'A' <= c && c <= 'Z' {
+			index[c+'a'-'A'] = byte(i)
+		}
+	}
+	return index
+}()
+
+// EncodeSortable 使用 Crockford Base32 将 ID 编码为定长 13 个字符的字符串，
+// 字符串的字典序与 ID 的数值顺序（即时间顺序）一致，适合作为 KV 存储中可范围扫描的 key
+// 负数按其补码编码，排在所有非负数之后
+func EncodeSortable(id int64) string {
+	n := uint64(id)
+
+	var buf [sortableWidth]byte
+	for i := len(buf) - 1; i >= 0; i-- {
+		buf[i] = sortableAlphabet[n&0x1F]
+		n >>= 5
+	}
+
+	return string(buf[:])
+}
+
+// DecodeSortable 解码 EncodeSortable 生成的字符串（不区分大小写）
+// 空字符串、长度不是 13、包含非法字符或超出 64 位时返回错误
+func DecodeSortable(s string) (int64, error) {
+	if len(s) != sortableWidth {
+		return 0, fmt.Errorf("snowflake: invalid sortable id %q, want %d characters", s, sortableWidth)
+	}
+
+	var n uint64
+	for i := 0; i < len(s); i++ {
+		v := sortableIndex[s[i]]
+		if v == 0xFF {
+			return 0, fmt.Errorf("snowflake: invalid sortable character %q in %q", s[i], s)
+		}
+		// 13 个字符共 65 位，第一个字符只能使用低 4 位
+		if i == 0 && v > 0xF {
+			return 0, fmt.Errorf("snowflake: sortable id %q overflows 64 bits", s)
+		}
+		n = n<<5 | uint64(v)
+	}
+
+	return int64(n), nil
+}
+
+// EncodeBase62 使用 [0-9A-Za-z] 将 ID 编码为 base62 字符串
+// NextID 生成的 ID 不会是负数，负数会带上 - 前缀
+func EncodeBase62(id int64) string {
+	if id == 0 {
+		return "0"
+	}
+
+	n := uint64(id)
+	if id < 0 {
+		n = uint64(-id)
+	}
+
+	// int64 的 base62 最多 11 位，外加符号位
+	var buf [12]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = base62Alphabet[n%62]
+		n /= 62
+	}
+	if id < 0 {
+		i--
+		buf[i] = '-'
+	}
+
+	return string(buf[i:])
+}
+
+// DecodeBase62 解码 EncodeBase62 生成的字符串，包含非法字符或超出 int64 范围时返回错误
+func DecodeBase62(s string) (int64, error) {
+	if s == "" {
+		return 0, errors.New("snowflake: empty base62 string")
+	}
+
+	digits, negative := s, false
+	if s[0] == '-' {
+		digits, negative = s[1:], true
+		if digits == "" {
+			return 0, fmt.Errorf("snowflake: invalid base62 string %q", s)
+		}
+	}
+
+	// 负数的绝对值最大可以是 1<<63
+	limit := uint64(math.MaxInt64)
+	if negative {
+		limit++
+	}
+
+	var n uint64
+	for i := 0; i < len(digits); i++ {
+		v := base62Index[digits[i]]
+		if v == 0xFF {
+			return 0, fmt.Errorf("snowflake: invalid base62 character %q in %q", digits[i], s)
+		}
+		if n > (limit-uint64(v))/62 {
+			return 0, fmt.Errorf("snowflake: base62 string %q overflows int64", s)
+		}
+		n = n*62 + uint64(v)
+	}
+
+	if negative {
+		return -int64(n), nil
+	}
+	return int64(n), nil
+}
+
+// NextIDString 获取一个十进制字符串形式的 ID，出错情况同 NextID
+func (s *SnowFlake) NextIDString() (string, error) {
+	id, err := s.NextID()
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+// NextIDBytes 获取一个 8 字节大端序形式的 ID，出错情况同 NextID
+// 大端序保证字节序与数值大小一致，适合直接作为 KV 存储的 key，可以用 DecodeBytes 解码
+func (s *SnowFlake) NextIDBytes() ([]byte, error) {
+	id, err := s.NextID()
+	if err != nil {
+		return nil, err
+	}
+	return EncodeBytes(id), nil
+}
+
+// EncodeBytes 将 ID 编码为 8 字节大端序
+func EncodeBytes(id int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(id))
+	return b
+}
+
+// DecodeBytes 解码 EncodeBytes 生成的 8 字节大端序，长度不是 8 时返回错误
+func DecodeBytes(b []byte) (int64, error) {
+	if len(b) != 8 {
+		return 0, fmt.Errorf("snowflake: invalid id bytes length %d, want 8", len(b))
+	}
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
+
+// NextIDHex 获取一个 16 个字符的十六进制形式的 ID，出错情况同 NextID
+func (s *SnowFlake) NextIDHex() (string, error) {
+	id, err := s.NextID()
+	if err != nil {
+		return "", err
+	}
+	return EncodeHex(id), nil
+}
+
+// EncodeHex 将 ID 编码为定长 16 个字符、小写、不足补 0 的十六进制字符串
+// NextID 生成的 ID 不会是负数，负数按补码编码，DecodeHex 无法解码
+func EncodeHex(id int64) string {
+	const digits = "0123456789abcdef"
+
+	n := uint64(id)
+	var buf [16]byte
+	for i := len(buf) - 1; i >= 0; i-- {
+		buf[i] = digits[n&0xF]
+		n >>= 4
+	}
+
+	return string(buf[:])
+}
+
+// DecodeHex 解码十六进制形式的 ID，最多 16 个字符，不区分大小写
+// 包含非法字符或超出 int64 范围时返回错误
+func DecodeHex(s string) (int64, error) {
+	if s == "" || len(s) > 16 {
+		return 0, fmt.Errorf("snowflake: invalid hex id %q, want 1 to 16 characters", s)
+	}
+
+	n, err := strconv.ParseInt(s, 16, 64)
+	if err != nil || s[0] == '-' || s[0] == '+' {
+		return 0, fmt.Errorf("snowflake: invalid hex id %q", s)
+	}
+	return n, nil
+}
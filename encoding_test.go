@@ -0,0 +1,160 @@
+package snowflake_test
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestBase62(t *testing.T) {
+	sf := snowflake.New()
+
+	ids := []int64{0, 1, 61, 62, math.MaxInt64, -1, math.MinInt64, sf.MustNextID()}
+	for _, id := range ids {
+		s := snowflake.EncodeBase62(id)
+		got, err := snowflake.DecodeBase62(s)
+		if err != nil {
+			t.Errorf("DecodeBase62(%q) error: %v", s, err)
+			continue
+		}
+		if got != id {
+			t.Errorf("DecodeBase62(EncodeBase62(%d)) = %d", id, got)
+		}
+	}
+
+	if s := snowflake.EncodeBase62(62); s != "10" {
+		t.Errorf("EncodeBase62(62) = %q, want %q", s, "10")
+	}
+	if s := snowflake.EncodeBase62(math.MaxInt64); s != "AzL8n0Y58m7" {
+		t.Errorf("EncodeBase62(MaxInt64) = %q, want %q", s, "AzL8n0Y58m7")
+	}
+
+	for _, s := range []string{"", "-", "abc!", "AzL8n0Y58m8", "zzzzzzzzzzzz"} {
+		if _, err := snowflake.DecodeBase62(s); err == nil {
+			t.Errorf("DecodeBase62(%q) should return an error", s)
+		}
+	}
+}
+
+func TestNextIDStringAndBytes(t *testing.T) {
+	sf := snowflake.NewWith(testStartTime, 1, 2)
+
+	s, err := sf.NextIDString()
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := sf.NextIDBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 8 {
+		t.Fatalf("len(NextIDBytes) = %d, want 8", len(b))
+	}
+	second, err := snowflake.DecodeBytes(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second <= first {
+		t.Errorf("second id %d is not greater than first %d", second, first)
+	}
+
+	// 字节序与数值大小一致
+	if bytes.Compare(snowflake.EncodeBytes(first), b) >= 0 {
+		t.Error("encoded bytes do not sort in numeric order")
+	}
+
+	if _, err := snowflake.DecodeBytes(b[:7]); err == nil {
+		t.Error("DecodeBytes should reject 7 bytes")
+	}
+}
+
+func TestSortable(t *testing.T) {
+	sf := snowflake.New()
+
+	ids := []int64{0, 1, 31, 32, 1 << 40, math.MaxInt64, -1, math.MinInt64}
+	batch, err := sf.NextIDs(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids = append(ids, batch...)
+	for _, id := range ids {
+		s := snowflake.EncodeSortable(id)
+		if len(s) != 13 {
+			t.Errorf("EncodeSortable(%d) = %q, want 13 characters", id, s)
+		}
+		got, err := snowflake.DecodeSortable(s)
+		if err != nil {
+			t.Errorf("DecodeSortable(%q) error: %v", s, err)
+			continue
+		}
+		if got != id {
+			t.Errorf("DecodeSortable(EncodeSortable(%d)) = %d", id, got)
+		}
+	}
+
+	if got, err := snowflake.DecodeSortable(strings.ToLower(snowflake.EncodeSortable(math.MaxInt64))); err != nil || got != math.MaxInt64 {
+		t.Errorf("lower-case DecodeSortable = %d, %v, want %d", got, err, int64(math.MaxInt64))
+	}
+
+	// 非负 ID 的字符串顺序与数值顺序一致
+	nonNegative := []int64{math.MaxInt64, 1 << 40, 32, 31, 1, 0}
+	encoded := make([]string, len(nonNegative))
+	for i, id := range nonNegative {
+		encoded[i] = snowflake.EncodeSortable(id)
+	}
+	sort.Strings(encoded)
+	for i, s := range encoded {
+		got, _ := snowflake.DecodeSortable(s)
+		if want := nonNegative[len(nonNegative)-1-i]; got != want {
+			t.Errorf("sorted[%d] = %d, want %d", i, got, want)
+		}
+	}
+
+	for _, s := range []string{"", "000000000000", "0000000000000U", "000000000000I", "G000000000000"} {
+		if _, err := snowflake.DecodeSortable(s); err == nil {
+			t.Errorf("DecodeSortable(%q) should return an error", s)
+		}
+	}
+}
+
+func TestHex(t *testing.T) {
+	sf := snowflake.New()
+
+	for _, id := range []int64{0, 1, 255, math.MaxInt64, sf.MustNextID()} {
+		s := snowflake.EncodeHex(id)
+		if len(s) != 16 || strings.ToLower(s) != s {
+			t.Errorf("EncodeHex(%d) = %q, want 16 lower-case characters", id, s)
+		}
+		got, err := snowflake.DecodeHex(s)
+		if err != nil {
+			t.Errorf("DecodeHex(%q) error: %v", s, err)
+			continue
+		}
+		if got != id {
+			t.Errorf("DecodeHex(EncodeHex(%d)) = %d", id, got)
+		}
+	}
+
+	if s := snowflake.EncodeHex(255); s != "00000000000000ff" {
+		t.Errorf("EncodeHex(255) = %q, want %q", s, "00000000000000ff")
+	}
+	if s, err := sf.NextIDHex(); err != nil || len(s) != 16 {
+		t.Errorf("NextIDHex = %q, %v, want 16 characters", s, err)
+	}
+
+	for _, s := range []string{"", "xyz", "-1", "+1", "8000000000000000", "00000000000000000"} {
+		if _, err := snowflake.DecodeHex(s); err == nil {
+			t.Errorf("DecodeHex(%q) should return an error", s)
+		}
+	}
+}
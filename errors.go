@@ -0,0 +1,61 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 以下错误返回时可能带有说明具体情况的上下文，应使用 errors.Is 判断，而不是直接比较或匹配错误信息
+
+// ErrTimestampOverflow 当前时间与开始时间的差值超出了时间戳的位数（默认 41 位，约 69 年）
+// 继续生成会让时间戳溢出到机器 ID 的位上，因此拒绝生成
+var ErrTimestampOverflow = errors.New("snowflake: timestamp overflows the timestamp bits, refusing to generate id")
+
+// ErrClockBackwards 时钟回拨超出了可容忍的范围，具体的回拨量见 *ClockBackwardsError，
+// errors.Is(err, ErrClockBackwards) 对 *ClockBackwardsError 成立
+var ErrClockBackwards = errors.New("snowflake: clock moved backwards")
+
+// ErrSequenceExhausted 当前毫秒内的序号已经用完，只由不等待下一毫秒的 TryNextID 返回
+var ErrSequenceExhausted = errors.New("snowflake: sequence exhausted in the current millisecond")
+
+// ErrNodeIDOutOfRange dataCenterID、workerID 或节点 ID 超出了位分配的范围，由 NewWithStrict、NewWithNode、NewWithOptions 等返回
+var ErrNodeIDOutOfRange = errors.New("snowflake: node id out of range")
+
+// ErrZeroMachineID 自动获取的机器 ID 是 0, 0，通常说明获取机器 ID 失败了，由 NewDefault 和 Validate 返回
+var ErrZeroMachineID = errors.New("snowflake: machine id resolved to dataCenterID 0, workerID 0, specify the node explicitly if this is intended")
+
+// ErrFutureStartTime 开始时间晚于当前时间，此时时间戳差值为负数，生成的 ID 没有意义
+var ErrFutureStartTime = errors.New("snowflake: start time is in the future")
+
+// ErrUnsignedLayout 使用 uint64 位分配（Config.Unsigned）的生成器只能通过 NextUID 生成 ID
+var ErrUnsignedLayout = errors.New("snowflake: generator uses an unsigned layout, use NextUID")
+
+// ErrClosed 生成器已经通过 Close 关闭
+var ErrClosed = errors.New("snowflake: generator is closed")
+
+// futureStartTimeError 包装 ErrFutureStartTime，说明开始时间和当前时间
+func futureStartTimeError(startTime, now time.Time) error {
+	return fmt.Errorf("%w: %s is after %s", ErrFutureStartTime, startTime.UTC(), now.UTC())
+}
+
+// ClockBackwardsError 时钟回拨错误，Delta 是回拨的毫秒数（使用微秒时间戳时为微秒数）
+// 调用方可根据 Delta 决定是等待重试还是直接失败
+type ClockBackwardsError struct {
+	Delta int64
+
+	// Delta 的单位，为 0 时表示毫秒
+	unit time.Duration
+}
+
+func (e *ClockBackwardsError) Error() string {
+	if e.unit == time.Microsecond {
+		return fmt.Sprintf("snowflake: clock moved backwards by %dµs, refusing to generate id", e.Delta)
+	}
+	return fmt.Sprintf("snowflake: clock moved backwards by %dms, refusing to generate id", e.Delta)
+}
+
+// Is 使 errors.Is(err, ErrClockBackwards) 成立
+func (e *ClockBackwardsError) Is(target error) bool {
+	return target == ErrClockBackwards
+}
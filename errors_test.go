@@ -0,0 +1,72 @@
+package snowflake_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestErrorsIs(t *testing.T) {
+	// 时钟回拨
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(testNow, testNow.Add(-5*time.Second)), 1, 2)
+	sf.MustNextID()
+	if _, err := sf.NextIDSafe(); !errors.Is(err, snowflake.ErrClockBackwards) {
+		t.Errorf("err = %v, want ErrClockBackwards", err)
+	}
+
+	// 时间戳溢出，错误中带有上下文
+	sf = snowflake.NewWithClock(testStartTime, newFakeClock(testStartTime.Add(time.Duration(1<<41)*time.Millisecond)), 1, 2)
+	_, err := sf.NextID()
+	if !errors.Is(err, snowflake.ErrTimestampOverflow) || err == snowflake.ErrTimestampOverflow {
+		t.Errorf("err = %v, want ErrTimestampOverflow wrapped with context", err)
+	}
+
+	// 节点 ID 超出范围
+	if _, err := snowflake.NewWithStrict(testStartTime, 32, 0); !errors.Is(err, snowflake.ErrNodeIDOutOfRange) {
+		t.Errorf("NewWithStrict err = %v, want ErrNodeIDOutOfRange", err)
+	}
+	if _, err := snowflake.NewWithNode(testStartTime, 1024); !errors.Is(err, snowflake.ErrNodeIDOutOfRange) {
+		t.Errorf("NewWithNode err = %v, want ErrNodeIDOutOfRange", err)
+	}
+	if _, err := snowflake.NewWithOptions(snowflake.WithDataCenterID(1), snowflake.WithWorkerID(40)); !errors.Is(err, snowflake.ErrNodeIDOutOfRange) {
+		t.Errorf("NewWithOptions err = %v, want ErrNodeIDOutOfRange", err)
+	}
+
+	// 开始时间在将来
+	if _, err := snowflake.NewWithStrict(time.Now().Add(time.Hour), 1, 2); !errors.Is(err, snowflake.ErrFutureStartTime) {
+		t.Errorf("NewWithStrict err = %v, want ErrFutureStartTime", err)
+	}
+}
+
+func TestTryNextID(t *testing.T) {
+	// 第 4097 次调用会读三次时钟：生成、发现序号用完和检查是否需要等待
+	times := append(repeatTime(testNow, 4099), testNow.Add(time.Millisecond))
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(times...), 1, 2)
+	for i := 0; i < 4096; i++ {
+		if _, err := sf.TryNextID(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 序号用完时不等待
+	if _, err := sf.TryNextID(); !errors.Is(err, snowflake.ErrSequenceExhausted) {
+		t.Fatalf("err = %v, want ErrSequenceExhausted", err)
+	}
+	// 时钟进入下一毫秒后可以继续生成
+	id, err := sf.TryNextID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := sf.Decompose(id); p.Sequence != 0 || !p.Time.Equal(testNow.Add(time.Millisecond)) {
+		t.Errorf("id after exhaustion = %s, want sequence 0 in the next millisecond", p)
+	}
+
+	// 需要等待时钟追上的回拨同样不等待
+	sf = snowflake.NewWithClock(testStartTime, newFakeClock(testNow, testNow.Add(-500*time.Millisecond)), 1, 2)
+	sf.MustNextID()
+	if _, err := sf.TryNextID(); !errors.Is(err, snowflake.ErrClockBackwards) {
+		t.Errorf("err = %v, want ErrClockBackwards", err)
+	}
+}
@@ -0,0 +1,15 @@
+package snowflake
+
+// 导出内部函数，供 snowflake_test 包中的测试使用
+var (
+	NodeFromAddrs   = nodeFromAddrs
+	SetStateForTest = (*SnowFlake).setStateForTest
+	NewDefaultWith  = newDefault
+)
+
+// BackfillLen 返回 GenerateAt 记录已用序号的毫秒数
+func (s *SnowFlake) BackfillLen() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.backfill)
+}
@@ -0,0 +1,114 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Generator ID 生成器，*SnowFlake、*AtomicSnowFlake、*MultiNodeSnowFlake、*Sharded 和 *SharedSnowFlake 都实现了该接口
+// 依赖 Generator 而不是具体类型，测试时可以替换为 MockGenerator；除了生成 ID，也可以按生成器的开始时间和位分配解码 ID
+type Generator interface {
+	NextID() (int64, error)
+	MustNextID() int64
+	NextIDContext(ctx context.Context) (int64, error)
+
+	TimeOf(id int64) time.Time
+	Decompose(id int64) Parts
+}
+
+var (
+	_ Generator = (*SnowFlake)(nil)
+	_ Generator = (*AtomicSnowFlake)(nil)
+	_ Generator = (*MultiNodeSnowFlake)(nil)
+	_ Generator = (*Sharded)(nil)
+	_ Generator = (*SharedSnowFlake)(nil)
+	_ Generator = (*MockGenerator)(nil)
+)
+
+// errMockExhausted MockGenerator 预设的 ID 已经用完
+var errMockExhausted = errors.New("snowflake: MockGenerator has no more ids")
+
+// MockGenerator 按顺序返回预设的 ID，用于测试，并发安全
+// TimeOf 和 Decompose 按默认位分配和开始时间（默认 DefaultEpoch，见 SetStartTime）解码
+type MockGenerator struct {
+	mutex     sync.Mutex
+	ids       []int64
+	err       error
+	startTime time.Time
+}
+
+// NewMockGenerator 创建按顺序返回 ids 的 MockGenerator
+func NewMockGenerator(ids ...int64) *MockGenerator {
+	return &MockGenerator{ids: ids, startTime: DefaultEpoch}
+}
+
+// SetStartTime 设置 TimeOf 和 Decompose 解码时使用的开始时间
+func (m *MockGenerator) SetStartTime(startTime time.Time) {
+	m.mutex.Lock()
+	m.startTime = startTime.UTC()
+	m.mutex.Unlock()
+}
+
+// TimeOf 按默认位分配返回 ID 的生成时间
+func (m *MockGenerator) TimeOf(id int64) time.Time {
+	return m.Decompose(id).Time
+}
+
+// Decompose 按默认位分配拆解 ID
+func (m *MockGenerator) Decompose(id int64) Parts {
+	m.mutex.Lock()
+	startTime := m.startTime
+	m.mutex.Unlock()
+	return defaultLayout.decompose(id, startTime)
+}
+
+// Push 追加预设的 ID
+func (m *MockGenerator) Push(ids ...int64) {
+	m.mutex.Lock()
+	m.ids = append(m.ids, ids...)
+	m.mutex.Unlock()
+}
+
+// SetError 设置之后 NextID 和 NextIDContext 都返回 err，传 nil 恢复正常
+func (m *MockGenerator) SetError(err error) {
+	m.mutex.Lock()
+	m.err = err
+	m.mutex.Unlock()
+}
+
+// NextID 返回下一个预设的 ID，预设的 ID 用完时返回错误
+func (m *MockGenerator) NextID() (int64, error) {
+	return m.NextIDContext(context.Background())
+}
+
+// MustNextID 同 NextID，但出错时 panic
+func (m *MockGenerator) MustNextID() int64 {
+	id, err := m.NextID()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// NextIDContext 同 NextID，ctx 已被取消时返回 ctx.Err()
+func (m *MockGenerator) NextIDContext(ctx context.Context) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.err != nil {
+		return 0, m.err
+	}
+	if len(m.ids) == 0 {
+		return 0, errMockExhausted
+	}
+
+	id := m.ids[0]
+	m.ids = m.ids[1:]
+	return id, nil
+}
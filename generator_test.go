@@ -0,0 +1,76 @@
+package snowflake_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestMockGenerator(t *testing.T) {
+	var g snowflake.Generator = snowflake.NewMockGenerator(3, 1)
+
+	if id := g.MustNextID(); id != 3 {
+		t.Errorf("NextID = %d, want 3", id)
+	}
+	if id, err := g.NextID(); id != 1 || err != nil {
+		t.Errorf("NextIDSafe = %d, %v, want 1, nil", id, err)
+	}
+	if _, err := g.NextID(); err == nil {
+		t.Error("NextIDSafe should return an error when ids are exhausted")
+	}
+
+	m := g.(*snowflake.MockGenerator)
+	m.Push(5)
+	wantErr := errors.New("boom")
+	m.SetError(wantErr)
+	if _, err := g.NextID(); err != wantErr {
+		t.Errorf("err = %v, want %v", err, wantErr)
+	}
+	m.SetError(nil)
+	if id := g.MustNextID(); id != 5 {
+		t.Errorf("NextID = %d, want 5", id)
+	}
+}
+
+func TestGeneratorDecode(t *testing.T) {
+	multi, err := snowflake.NewWithNodes(testStartTime, []uint16{10, 11})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer multi.Close()
+	sharded, err := snowflake.NewSharded(testStartTime, []uint16{12, 13})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sharded.Close()
+
+	for name, g := range map[string]snowflake.Generator{
+		"mutex":  snowflake.NewWith(testStartTime, 1, 2),
+		"atomic": snowflake.NewAtomic(testStartTime, 1, 2),
+		"multi":  multi,
+		"shard":  sharded,
+	} {
+		before := time.Now().Add(-time.Millisecond)
+		id := g.MustNextID()
+		if got := g.TimeOf(id); got.Before(before) || got.After(time.Now()) {
+			t.Errorf("%s: TimeOf = %s, want around now", name, got)
+		}
+		if got, want := g.Decompose(id).Time, g.TimeOf(id); !got.Equal(want) {
+			t.Errorf("%s: Decompose.Time = %s, want %s", name, got, want)
+		}
+	}
+
+	// MockGenerator 按默认位分配和设置的开始时间解码
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(testNow), 3, 4)
+	id := sf.MustNextID()
+	m := snowflake.NewMockGenerator(id)
+	m.SetStartTime(testStartTime)
+	if got, want := m.Decompose(id), sf.Decompose(id); got != want {
+		t.Errorf("MockGenerator.Decompose = %+v, want %+v", got, want)
+	}
+	if got := m.TimeOf(id); !got.Equal(testNow) {
+		t.Errorf("MockGenerator.TimeOf = %s, want %s", got, testNow)
+	}
+}
@@ -0,0 +1,119 @@
+package snowflake
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ID 带类型的 snowflake ID，实现了 sql.Scanner 和 driver.Valuer，可直接用于 database/sql
+// 与 int64 之间可以直接转换：ID(sf.MustNextID())、int64(id)
+type ID int64
+
+// NextTypedID 获取一个 ID 类型的 ID，出错情况同 NextID
+func (s *SnowFlake) NextTypedID() (ID, error) {
+	id, err := s.NextID()
+	return ID(id), err
+}
+
+// Int64 返回 int64 形式的 ID
+func (id ID) Int64() int64 {
+	return int64(id)
+}
+
+// 以下方法按默认位分配（DefaultConfig）解码 ID，不需要 *SnowFlake；自定义位分配的 ID 使用 ParseIDWithEpoch
+
+// Time 返回 ID 的生成时间（UTC），epochMs 是生成器的开始时间（Unix 毫秒，如 DefaultEpoch.UnixMilli()）
+func (id ID) Time(epochMs int64) time.Time {
+	elapsed, _, _, _ := defaultLayout.parse(int64(id))
+	return time.UnixMilli(epochMs + elapsed).UTC()
+}
+
+// Sequence 返回 ID 的毫秒内序号
+func (id ID) Sequence() int16 {
+	_, _, _, sequence := defaultLayout.parse(int64(id))
+	return int16(sequence)
+}
+
+// Node 返回 ID 的 10 位节点 ID，含义同 NewWithNode
+func (id ID) Node() uint16 {
+	_, dataCenterID, workerID, _ := defaultLayout.parse(int64(id))
+	return defaultLayout.joinNode(dataCenterID, workerID)
+}
+
+// String 返回十进制形式的 ID
+func (id ID) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// Value 实现 driver.Valuer
+func (id ID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
+
+// Scan 实现 sql.Scanner，支持 int64、[]byte 和 string（有些驱动以字节形式返回 BIGINT）
+func (id *ID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case int64:
+		*id = ID(v)
+		return nil
+	case []byte:
+		return id.parse(string(v))
+	case string:
+		return id.parse(v)
+	default:
+		return fmt.Errorf("snowflake: cannot scan %T into ID", src)
+	}
+}
+
+func (id *ID) parse(s string) error {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("snowflake: cannot scan %q into ID: %w", s, err)
+	}
+	*id = ID(n)
+	return nil
+}
+
+// MarshalJSON 实现 json.Marshaler，以字符串形式输出，避免 JavaScript 在超过 2^53 时丢失精度
+func (id ID) MarshalJSON() ([]byte, error) {
+	b := make([]byte, 0, 21)
+	b = append(b, '"')
+	b = strconv.AppendInt(b, int64(id), 10)
+	b = append(b, '"')
+	return b, nil
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler，同时支持字符串和数字形式，null 时保持不变
+func (id *ID) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("snowflake: cannot unmarshal %s into ID: %w", b, err)
+	}
+	*id = ID(n)
+	return nil
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler，输出 8 字节大端序（同 EncodeBytes），也用于 gob
+func (id ID) MarshalBinary() ([]byte, error) {
+	return EncodeBytes(int64(id)), nil
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler，长度不是 8 时返回错误
+func (id *ID) UnmarshalBinary(b []byte) error {
+	n, err := DecodeBytes(b)
+	if err != nil {
+		return err
+	}
+	*id = ID(n)
+	return nil
+}
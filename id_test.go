@@ -0,0 +1,140 @@
+package snowflake_test
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestIDScanValue(t *testing.T) {
+	sf := snowflake.New()
+	id, err := sf.NextTypedID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := id.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != driver.Value(int64(id)) {
+		t.Errorf("Value = %v, want %d", v, int64(id))
+	}
+
+	for _, src := range []interface{}{int64(id), []byte(id.String()), id.String()} {
+		var got snowflake.ID
+		if err := got.Scan(src); err != nil {
+			t.Errorf("Scan(%T) error: %v", src, err)
+			continue
+		}
+		if got != id {
+			t.Errorf("Scan(%T) = %d, want %d", src, got, id)
+		}
+	}
+
+	for _, src := range []interface{}{nil, 1.5, "abc", []byte("")} {
+		var got snowflake.ID
+		if err := got.Scan(src); err == nil {
+			t.Errorf("Scan(%#v) should return an error", src)
+		}
+	}
+}
+
+func TestIDJSON(t *testing.T) {
+	type payload struct {
+		ID snowflake.ID `json:"id"`
+	}
+
+	// 超过 2^53 的值在 JavaScript 中会丢失精度
+	id := snowflake.ID(1<<53 + 1)
+
+	b, err := json.Marshal(payload{ID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"id":"9007199254740993"}`; string(b) != want {
+		t.Errorf("Marshal = %s, want %s", b, want)
+	}
+
+	for _, in := range []string{string(b), `{"id":9007199254740993}`} {
+		var p payload
+		if err := json.Unmarshal([]byte(in), &p); err != nil {
+			t.Errorf("Unmarshal(%s) error: %v", in, err)
+			continue
+		}
+		if p.ID != id {
+			t.Errorf("Unmarshal(%s) = %d, want %d", in, p.ID, id)
+		}
+	}
+
+	for _, in := range []string{`{"id":"abc"}`, `{"id":1.5}`, `{"id":""}`} {
+		var p payload
+		if err := json.Unmarshal([]byte(in), &p); err == nil {
+			t.Errorf("Unmarshal(%s) should return an error", in)
+		}
+	}
+}
+
+var (
+	_ encoding.BinaryMarshaler   = snowflake.ID(0)
+	_ encoding.BinaryUnmarshaler = (*snowflake.ID)(nil)
+)
+
+func TestIDBinary(t *testing.T) {
+	id := snowflake.ID(snowflake.New().MustNextID())
+
+	b, err := id.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 8 {
+		t.Fatalf("len(MarshalBinary()) = %d, want 8", len(b))
+	}
+	var got snowflake.ID
+	if err := got.UnmarshalBinary(b); err != nil || got != id {
+		t.Errorf("UnmarshalBinary() = %d, %v, want %d", got, err, id)
+	}
+	if err := got.UnmarshalBinary(b[:7]); err == nil {
+		t.Error("UnmarshalBinary with 7 bytes should return an error")
+	}
+
+	type record struct {
+		ID   snowflake.ID
+		Name string
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(record{id, "a"}); err != nil {
+		t.Fatal(err)
+	}
+	var r record
+	if err := gob.NewDecoder(&buf).Decode(&r); err != nil {
+		t.Fatal(err)
+	}
+	if r.ID != id {
+		t.Errorf("gob round trip = %d, want %d", r.ID, id)
+	}
+}
+
+func TestIDDecode(t *testing.T) {
+	now := testNow.Add(123 * time.Millisecond)
+	dataCenterID, workerID := snowflake.SplitNodeID(613)
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(now), dataCenterID, workerID)
+
+	sf.MustNextID()
+	id := snowflake.ID(sf.MustNextID())
+	if got := id.Time(testStartTime.UnixMilli()); !got.Equal(now) {
+		t.Errorf("Time() = %s, want %s", got, now)
+	}
+	if got := id.Sequence(); got != 1 {
+		t.Errorf("Sequence() = %d, want 1", got)
+	}
+	if got := id.Node(); got != 613 {
+		t.Errorf("Node() = %d, want 613", got)
+	}
+}
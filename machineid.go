@@ -0,0 +1,177 @@
+package snowflake
+
+import (
+	"errors"
+	"hash/fnv"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MachineIDProvider 提供 dataCenterID 和 workerID
+// 可以基于 Redis、ZooKeeper 等实现集中分配，避免不同节点使用相同的 ID
+type MachineIDProvider interface {
+	MachineID() (dataCenterID, workerID uint8, err error)
+}
+
+// IPProvider 对第一个非回环 IPv4 地址（没有 IPv4 时使用 IPv6 地址）做 FNV 哈希得到 10 位的节点值，
+// 高 5 位作为 dataCenterID，低 5 位作为 workerID
+// 相比直接取 IP 的后两段，不同网段的机器（如 10.0.0.1 和 10.0.32.1）不会因截断而冲突
+type IPProvider struct{}
+
+// MachineID 实现 MachineIDProvider
+func (IPProvider) MachineID() (uint8, uint8, error) {
+	as, err := net.InterfaceAddrs()
+	if err != nil {
+		return 0, 0, err
+	}
+	return nodeFromAddrs(as)
+}
+
+// nodeFromAddrs 从 as 中选出一个非回环地址并哈希为 dataCenterID 和 workerID
+// 优先使用第一个 IPv4 地址；只有 IPv6 时，优先使用全局单播地址，其次是链路本地等其他地址
+func nodeFromAddrs(as []net.Addr) (uint8, uint8, error) {
+	var global, other net.IP
+	for _, a := range as {
+		ipnet, ok := a.(*net.IPNet)
+		if !ok || ipnet.IP.IsLoopback() || ipnet.IP.IsUnspecified() {
+			continue
+		}
+
+		if ip := ipnet.IP.To4(); ip != nil {
+			dataCenterID, workerID := hashNode(ip)
+			return dataCenterID, workerID, nil
+		}
+
+		ip := ipnet.IP.To16()
+		if ip == nil {
+			continue
+		}
+		if global == nil && ip.IsGlobalUnicast() {
+			global = ip
+		} else if other == nil {
+			other = ip
+		}
+	}
+
+	if global == nil {
+		global = other
+	}
+	if global == nil {
+		return 0, 0, errors.New("snowflake: no non-loopback IP address found")
+	}
+
+	dataCenterID, workerID := hashNode(global)
+	return dataCenterID, workerID, nil
+}
+
+// HostnameProvider 对主机名做 FNV 哈希得到 10 位的节点值，高 5 位作为 dataCenterID，低 5 位作为 workerID
+type HostnameProvider struct{}
+
+// MachineID 实现 MachineIDProvider
+func (HostnameProvider) MachineID() (uint8, uint8, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	dataCenterID, workerID := hashNode([]byte(hostname))
+	return dataCenterID, workerID, nil
+}
+
+// MachineIDFromString 对 s 做 FNV 哈希得到 10 位的节点值，高 5 位作为 dataCenterID，低 5 位作为 workerID
+// 相同的 s 总是得到相同的结果，适合 Kubernetes 中以稳定的 Pod 名称（POD_NAME、HOSTNAME）作为节点标识
+func MachineIDFromString(s string) (dataCenterID, workerID uint8) {
+	return hashNode([]byte(s))
+}
+
+// StringProvider 使用 MachineIDFromString 哈希给定的字符串，如 StringProvider(os.Getenv("POD_NAME"))
+// 字符串为空时返回错误，便于在 ChainProvider 中回退到其他方式
+type StringProvider string
+
+// MachineID 实现 MachineIDProvider
+func (p StringProvider) MachineID() (uint8, uint8, error) {
+	if p == "" {
+		return 0, 0, errors.New("snowflake: empty StringProvider")
+	}
+	dataCenterID, workerID := MachineIDFromString(string(p))
+	return dataCenterID, workerID, nil
+}
+
+// hashNode 对 b 做 FNV 哈希得到 10 位的节点值，拆分为 5 位的 dataCenterID 和 5 位的 workerID
+func hashNode(b []byte) (uint8, uint8) {
+	h := fnv.New32a()
+	h.Write(b)
+	node := uint16(h.Sum32() & 0x3FF)
+
+	return uint8(node >> 5), uint8(node & 0x1F)
+}
+
+// NewWithProvider 给定开始时间，使用 p 获取 dataCenterID 和 workerID
+func NewWithProvider(startTime time.Time, p MachineIDProvider) (*SnowFlake, error) {
+	dataCenterID, workerID, err := p.MachineID()
+	if err != nil {
+		return nil, err
+	}
+	s := NewWith(startTime, dataCenterID, workerID)
+	s.explicitNode = false
+	return s, nil
+}
+
+// ChainProvider 依次尝试其中的 MachineIDProvider，返回第一个成功的结果，全部失败时返回最后一个错误
+type ChainProvider []MachineIDProvider
+
+// MachineID 实现 MachineIDProvider
+func (c ChainProvider) MachineID() (uint8, uint8, error) {
+	err := errors.New("snowflake: empty ChainProvider")
+	for _, p := range c {
+		var dataCenterID, workerID uint8
+		if dataCenterID, workerID, err = p.MachineID(); err == nil {
+			return dataCenterID, workerID, nil
+		}
+	}
+	return 0, 0, err
+}
+
+// RandomProvider 使用进程内随机生成一次的 10 位节点值，同一进程内每次返回相同的结果
+// 不同进程之间只是大概率不同，仅适合作为兜底
+type RandomProvider struct{}
+
+var (
+	randomNodeOnce sync.Once
+	randomNode     uint16
+)
+
+// MachineID 实现 MachineIDProvider
+func (RandomProvider) MachineID() (uint8, uint8, error) {
+	randomNodeOnce.Do(func() {
+		randomNode = uint16(rand.New(rand.NewSource(time.Now().UnixNano())).Intn(1 << 10))
+	})
+	return uint8(randomNode >> 5), uint8(randomNode & 0x1F), nil
+}
+
+// ProcessProvider 对主机名和进程 ID 一起做 FNV 哈希得到 10 位的节点值，主机名获取不到时只哈希进程 ID，不会返回错误
+// 同一主机上的不同进程通常会得到不同的节点值，但这只是尽力而为：10 位的哈希值可能冲突，进程 ID 也会被复用，
+// 不能保证唯一，需要保证时应使用集中分配的 MachineIDProvider
+type ProcessProvider struct{}
+
+// MachineID 实现 MachineIDProvider
+func (ProcessProvider) MachineID() (uint8, uint8, error) {
+	hostname, _ := os.Hostname()
+	dataCenterID, workerID := hashNode([]byte(hostname + "/" + strconv.Itoa(os.Getpid())))
+	return dataCenterID, workerID, nil
+}
+
+// defaultProvider New、NewWith 等未指定机器 ID 时使用的 MachineIDProvider，按以下顺序尝试：
+//  1. IPProvider：哈希第一个非回环 IPv4 地址，没有 IPv4 时哈希 IPv6 地址
+//  2. ProcessProvider：没有可用的 IP 地址（如只有回环地址）时，哈希主机名和进程 ID，
+//     同一主机上的多个进程不会像只哈希主机名那样总是得到相同的节点值
+var defaultProvider MachineIDProvider = ChainProvider{IPProvider{}, ProcessProvider{}}
+
+// machineID 使用 defaultProvider 获取 dataCenterID 和 workerID
+func machineID() (uint8, uint8, error) {
+	return defaultProvider.MachineID()
+}
@@ -0,0 +1,188 @@
+package snowflake_test
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/polaris1119/snowflake"
+)
+
+type stubProvider struct {
+	dataCenterID, workerID uint8
+	err                    error
+}
+
+func (p stubProvider) MachineID() (uint8, uint8, error) {
+	return p.dataCenterID, p.workerID, p.err
+}
+
+func TestNewWithProvider(t *testing.T) {
+	sf, err := snowflake.NewWithProvider(testStartTime, stubProvider{dataCenterID: 3, workerID: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sf.DataCenterID() != 3 || sf.WorkerID() != 9 {
+		t.Errorf("DataCenterID, WorkerID = %d, %d, want 3, 9", sf.DataCenterID(), sf.WorkerID())
+	}
+
+	wantErr := errors.New("lease unavailable")
+	if _, err := snowflake.NewWithProvider(testStartTime, stubProvider{err: wantErr}); err != wantErr {
+		t.Errorf("err = %v, want %v", err, wantErr)
+	}
+}
+
+func TestHostnameProvider(t *testing.T) {
+	dataCenterID, workerID, err := snowflake.HostnameProvider{}.MachineID()
+	if err != nil {
+		t.Skip(err)
+	}
+	if dataCenterID > 31 || workerID > 31 {
+		t.Errorf("dataCenterID, workerID = %d, %d, want both <= 31", dataCenterID, workerID)
+	}
+
+	// 同一主机名总是得到相同的结果
+	dc2, worker2, _ := snowflake.HostnameProvider{}.MachineID()
+	if dc2 != dataCenterID || worker2 != workerID {
+		t.Errorf("HostnameProvider is not deterministic: %d,%d vs %d,%d", dataCenterID, workerID, dc2, worker2)
+	}
+}
+
+func TestMachineIDFromString(t *testing.T) {
+	dataCenterID, workerID := snowflake.MachineIDFromString("web-7f9c-0")
+	if dataCenterID > 31 || workerID > 31 {
+		t.Errorf("dataCenterID, workerID = %d, %d, want both <= 31", dataCenterID, workerID)
+	}
+	if dc2, worker2 := snowflake.MachineIDFromString("web-7f9c-0"); dc2 != dataCenterID || worker2 != workerID {
+		t.Errorf("MachineIDFromString is not deterministic: %d,%d vs %d,%d", dataCenterID, workerID, dc2, worker2)
+	}
+	if dc2, worker2 := snowflake.MachineIDFromString("web-7f9c-1"); dc2 == dataCenterID && worker2 == workerID {
+		t.Errorf("different pod names map to the same node %d,%d", dc2, worker2)
+	}
+
+	got1, got2, err := snowflake.StringProvider("web-7f9c-0").MachineID()
+	if err != nil || got1 != dataCenterID || got2 != workerID {
+		t.Errorf("StringProvider.MachineID() = %d, %d, %v, want %d, %d, nil", got1, got2, err, dataCenterID, workerID)
+	}
+	if _, _, err := snowflake.StringProvider("").MachineID(); err == nil {
+		t.Error("empty StringProvider should return an error")
+	}
+}
+
+func ipnet(s string) net.Addr {
+	ip := net.ParseIP(s)
+	if ip.To4() != nil {
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(24, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(64, 128)}
+}
+
+func TestNodeFromAddrs(t *testing.T) {
+	// 直接取后两段并截断到 5 位时，这两个地址会冲突
+	dc1, worker1, err := snowflake.NodeFromAddrs([]net.Addr{ipnet("127.0.0.1"), ipnet("10.0.0.1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dc2, worker2, err := snowflake.NodeFromAddrs([]net.Addr{ipnet("10.0.32.1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dc1 == dc2 && worker1 == worker2 {
+		t.Errorf("10.0.0.1 and 10.0.32.1 both map to %d,%d", dc1, worker1)
+	}
+	if dc1 > 31 || worker1 > 31 || dc2 > 31 || worker2 > 31 {
+		t.Errorf("node ids out of range: %d,%d %d,%d", dc1, worker1, dc2, worker2)
+	}
+
+	if _, _, err := snowflake.NodeFromAddrs([]net.Addr{ipnet("127.0.0.1"), ipnet("::1")}); err == nil {
+		t.Error("only loopback addresses should return an error")
+	}
+}
+
+func TestNodeFromAddrsIPv6(t *testing.T) {
+	nodeOf := func(addrs ...string) uint16 {
+		as := make([]net.Addr, len(addrs))
+		for i, a := range addrs {
+			as[i] = ipnet(a)
+		}
+		dataCenterID, workerID, err := snowflake.NodeFromAddrs(as)
+		if err != nil {
+			t.Fatalf("%v: %v", addrs, err)
+		}
+		if dataCenterID > 31 || workerID > 31 {
+			t.Fatalf("%v: node ids out of range: %d,%d", addrs, dataCenterID, workerID)
+		}
+		return uint16(dataCenterID)<<5 | uint16(workerID)
+	}
+
+	// IPv4 优先
+	if nodeOf("2001:db8::1", "10.0.0.1") != nodeOf("10.0.0.1") {
+		t.Error("IPv4 address should take precedence over IPv6")
+	}
+	// 全局单播地址优先于链路本地地址
+	if nodeOf("::1", "fe80::1", "2001:db8::1") != nodeOf("2001:db8::1") {
+		t.Error("global unicast address should take precedence over link-local")
+	}
+	// 只有链路本地地址时也能得到节点 ID
+	nodeOf("fe80::1")
+
+	// 不同的 IPv6 地址大多映射到不同的节点 ID
+	nodes := make(map[uint16]bool)
+	for i := 0; i < 64; i++ {
+		nodes[nodeOf(net.IP{0x20, 0x01, 0x0d, 0xb8, 15: byte(i)}.String())] = true
+	}
+	if len(nodes) < 48 {
+		t.Errorf("64 IPv6 addresses map to only %d distinct node ids", len(nodes))
+	}
+}
+
+func TestChainProvider(t *testing.T) {
+	failed := stubProvider{err: errors.New("no ip")}
+
+	dataCenterID, workerID, err := snowflake.ChainProvider{failed, stubProvider{dataCenterID: 4, workerID: 5}, stubProvider{dataCenterID: 6}}.MachineID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dataCenterID != 4 || workerID != 5 {
+		t.Errorf("dataCenterID, workerID = %d, %d, want 4, 5", dataCenterID, workerID)
+	}
+
+	if _, _, err := (snowflake.ChainProvider{failed}).MachineID(); err != failed.err {
+		t.Errorf("err = %v, want %v", err, failed.err)
+	}
+	if _, _, err := (snowflake.ChainProvider{}).MachineID(); err == nil {
+		t.Error("empty ChainProvider should return an error")
+	}
+}
+
+func TestRandomProvider(t *testing.T) {
+	dataCenterID, workerID, err := snowflake.RandomProvider{}.MachineID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dataCenterID > 31 || workerID > 31 {
+		t.Errorf("dataCenterID, workerID = %d, %d, want both <= 31", dataCenterID, workerID)
+	}
+
+	// 同一进程内只随机一次
+	dc2, worker2, _ := snowflake.RandomProvider{}.MachineID()
+	if dc2 != dataCenterID || worker2 != workerID {
+		t.Errorf("RandomProvider changed within a process: %d,%d vs %d,%d", dataCenterID, workerID, dc2, worker2)
+	}
+}
+
+func TestProcessProvider(t *testing.T) {
+	dataCenterID, workerID, err := snowflake.ProcessProvider{}.MachineID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 与主机名加进程 ID 的哈希一致，而不是只哈希主机名
+	hostname, _ := os.Hostname()
+	wantDC, wantWorker := snowflake.MachineIDFromString(hostname + "/" + strconv.Itoa(os.Getpid()))
+	if dataCenterID != wantDC || workerID != wantWorker {
+		t.Errorf("ProcessProvider = %d, %d, want %d, %d", dataCenterID, workerID, wantDC, wantWorker)
+	}
+}
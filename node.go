@@ -0,0 +1,150 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// NewWithNode 给定开始时间和 10 位的节点 ID（0-1023），
+// 高 5 位作为 dataCenterID，低 5 位作为 workerID，适合 Kubernetes StatefulSet 序号这类单一的节点编号
+// 与 NewWithStrict 一样，同一进程内该节点已被未 Close 的生成器使用时返回错误
+func NewWithNode(startTime time.Time, nodeID uint16) (*SnowFlake, error) {
+	if err := defaultLayout.checkNodeID(nodeID); err != nil {
+		return nil, err
+	}
+	dataCenterID, workerID := defaultLayout.splitNode(nodeID)
+	s := newWith(defaultLayout, startTime, dataCenterID, workerID)
+	if err := s.register(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NodeID 按默认位分配将 dataCenterID 和 workerID 合并为 NewWithNode 使用的 10 位节点 ID
+// 与 NewWith 一样，超出 5 位的 dataCenterID 和 workerID 会被截掉高位
+func NodeID(dataCenterID, workerID uint8) uint16 {
+	return defaultLayout.joinNode(dataCenterID&uint8(defaultLayout.dataCenterMask), workerID&uint8(defaultLayout.workerMask))
+}
+
+// SplitNodeID 按默认位分配将节点 ID 拆分为 dataCenterID 和 workerID，是 NodeID 的逆运算
+// 超出 10 位的部分会被忽略
+func SplitNodeID(nodeID uint16) (dataCenterID, workerID uint8) {
+	return defaultLayout.splitNode(nodeID)
+}
+
+// MultiNodeSnowFlake 拥有多个节点 ID 的生成器，每次生成依次轮换使用其中一个节点，
+// 每个节点有各自的序号和上次时间戳，每毫秒的容量是单个节点的 len(nodeIDs) 倍
+type MultiNodeSnowFlake struct {
+	// 放在第一个字段，保证 32 位平台上 64 位原子操作的对齐
+	next  uint64
+	nodes []*SnowFlake
+}
+
+// NewWithNodes 给定开始时间和多个节点 ID（含义同 NewWithNode），创建轮换使用这些节点的生成器
+// 节点 ID 不能为空，也不能重复或超出范围；这些节点 ID 不能再分配给其他生成器
+// 生成的 ID 不会重复，但来自不同节点的 ID 只按时间有序，而不是严格递增
+func NewWithNodes(startTime time.Time, nodeIDs []uint16) (*MultiNodeSnowFlake, error) {
+	if len(nodeIDs) == 0 {
+		return nil, errors.New("snowflake: nodeIDs must not be empty")
+	}
+
+	seen := make(map[uint16]bool, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		if seen[nodeID] {
+			return nil, fmt.Errorf("snowflake: duplicate nodeID %d", nodeID)
+		}
+		seen[nodeID] = true
+	}
+
+	nodes := make([]*SnowFlake, 0, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		sf, err := NewWithNode(startTime, nodeID)
+		if err != nil {
+			for _, node := range nodes {
+				node.Close()
+			}
+			return nil, err
+		}
+		nodes = append(nodes, sf)
+	}
+
+	return &MultiNodeSnowFlake{nodes: nodes}, nil
+}
+
+// Close 关闭所有节点的生成器，释放进程内登记的节点，可以多次调用
+func (m *MultiNodeSnowFlake) Close() error {
+	for _, sf := range m.nodes {
+		sf.Close()
+	}
+	return nil
+}
+
+// NextID 获取一个 ID，出错情况同 SnowFlake.NextID
+func (m *MultiNodeSnowFlake) NextID() (int64, error) {
+	return m.NextIDContext(context.Background())
+}
+
+// MustNextID 同 NextID，但出错时 panic
+func (m *MultiNodeSnowFlake) MustNextID() int64 {
+	id, err := m.NextID()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// NextIDContext 同 NextID，ctx 的含义同 SnowFlake.NextIDContext
+func (m *MultiNodeSnowFlake) NextIDContext(ctx context.Context) (int64, error) {
+	i := (atomic.AddUint64(&m.next, 1) - 1) % uint64(len(m.nodes))
+	return m.nodes[i].NextIDContext(ctx)
+}
+
+// TimeOf 同 SnowFlake.TimeOf，各节点的开始时间和位分配相同，与 ID 来自哪个节点无关
+func (m *MultiNodeSnowFlake) TimeOf(id int64) time.Time {
+	return m.nodes[0].TimeOf(id)
+}
+
+// Decompose 同 SnowFlake.Decompose
+func (m *MultiNodeSnowFlake) Decompose(id int64) Parts {
+	return m.nodes[0].Decompose(id)
+}
+
+// NodeIDs 返回生成器使用的节点 ID
+func (m *MultiNodeSnowFlake) NodeIDs() []uint16 {
+	ids := make([]uint16, len(m.nodes))
+	for i, sf := range m.nodes {
+		ids[i] = sf.NodeID()
+	}
+	return ids
+}
+
+// NodeID 返回 dataCenterID 和 workerID 合并后的节点 ID
+func (s *SnowFlake) NodeID() uint16 {
+	return s.layout.joinNode(s.dataCenterID, s.workerID)
+}
+
+// nodeBits 节点 ID 的位数
+func (l layout) nodeBits() uint {
+	return l.config.DataCenterBits + l.config.WorkerBits
+}
+
+// checkNodeID 校验节点 ID 是否在位分配的范围内
+func (l layout) checkNodeID(nodeID uint16) error {
+	if max := uint16(1<<l.nodeBits() - 1); nodeID > max {
+		return fmt.Errorf("%w: nodeID %d not in [0, %d]", ErrNodeIDOutOfRange, nodeID, max)
+	}
+	return nil
+}
+
+// splitNode 将节点 ID 拆分为 dataCenterID 和 workerID
+func (l layout) splitNode(nodeID uint16) (uint8, uint8) {
+	return uint8(int64(nodeID>>l.config.WorkerBits) & l.dataCenterMask), uint8(int64(nodeID) & l.workerMask)
+}
+
+// joinNode 将 dataCenterID 和 workerID 合并为节点 ID
+func (l layout) joinNode(dataCenterID, workerID uint8) uint16 {
+	return uint16(dataCenterID)<<l.config.WorkerBits | uint16(workerID)
+}
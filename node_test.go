@@ -0,0 +1,111 @@
+package snowflake_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestNewWithNode(t *testing.T) {
+	sf, err := snowflake.NewWithNode(testStartTime, 1023)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sf.DataCenterID() != 31 || sf.WorkerID() != 31 {
+		t.Errorf("DataCenterID, WorkerID = %d, %d, want 31, 31", sf.DataCenterID(), sf.WorkerID())
+	}
+	sf.Close()
+
+	sf, err = snowflake.NewWithNode(testStartTime, 3<<5|7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	if sf.DataCenterID() != 3 || sf.WorkerID() != 7 {
+		t.Errorf("DataCenterID, WorkerID = %d, %d, want 3, 7", sf.DataCenterID(), sf.WorkerID())
+	}
+	if got := sf.NodeID(); got != 3<<5|7 {
+		t.Errorf("NodeID = %d, want %d", got, 3<<5|7)
+	}
+
+	if _, err := snowflake.NewWithNode(testStartTime, 1024); err == nil {
+		t.Error("nodeID 1024 should be rejected")
+	}
+}
+
+func TestNodeIDHelpers(t *testing.T) {
+	if got := snowflake.NodeID(3, 7); got != 3<<5|7 {
+		t.Errorf("NodeID(3, 7) = %d, want %d", got, 3<<5|7)
+	}
+	// 与 NewWith 一样截掉高位
+	if got := snowflake.NodeID(35, 40); got != 3<<5|8 {
+		t.Errorf("NodeID(35, 40) = %d, want %d", got, 3<<5|8)
+	}
+
+	for _, nodeID := range []uint16{0, 1, 3<<5 | 7, 1023} {
+		dataCenterID, workerID := snowflake.SplitNodeID(nodeID)
+		if got := snowflake.NodeID(dataCenterID, workerID); got != nodeID {
+			t.Errorf("NodeID(SplitNodeID(%d)) = %d", nodeID, got)
+		}
+
+		// 与 NextID 使用的位一致
+		sf, err := snowflake.NewWithNode(testStartTime, nodeID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, gotDataCenterID, gotWorkerID, _ := snowflake.ParseID(sf.MustNextID(), testStartTime)
+		if gotDataCenterID != dataCenterID || gotWorkerID != workerID {
+			t.Errorf("SplitNodeID(%d) = %d, %d, want %d, %d from id", nodeID, dataCenterID, workerID, gotDataCenterID, gotWorkerID)
+		}
+		sf.Close()
+	}
+}
+
+func TestNewWithNodes(t *testing.T) {
+	m, err := snowflake.NewWithNodes(testStartTime, []uint16{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if got := m.NodeIDs(); len(got) != 3 || got[0] != 1 || got[2] != 3 {
+		t.Errorf("NodeIDs() = %v, want [1 2 3]", got)
+	}
+
+	// 依次轮换节点
+	for i := 0; i < 6; i++ {
+		_, dataCenterID, workerID, _ := snowflake.ParseID(m.MustNextID(), testStartTime)
+		if got, want := uint16(dataCenterID)<<5|uint16(workerID), uint16(i%3+1); got != want {
+			t.Errorf("id %d from node %d, want %d", i, got, want)
+		}
+	}
+
+	const goroutines, perGoroutine = 8, 3000
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		ids = make(map[int64]bool, goroutines*perGoroutine)
+	)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				id := m.MustNextID()
+				mu.Lock()
+				ids[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(ids) != goroutines*perGoroutine {
+		t.Errorf("got %d unique ids, want %d", len(ids), goroutines*perGoroutine)
+	}
+
+	for _, nodeIDs := range [][]uint16{nil, {1, 1}, {1, 1024}} {
+		if _, err := snowflake.NewWithNodes(testStartTime, nodeIDs); err == nil {
+			t.Errorf("NewWithNodes(%v) should return an error", nodeIDs)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package snowflake
+
+// feistelRounds Feistel 网络的轮数
+const feistelRounds = 8
+
+// Obfuscate 用 key 对 ID 做可逆的混淆，结果不再体现生成顺序和数量，适合对外暴露；用 Deobfuscate 还原
+// 混淆作用于低 63 位，符号位保持不变，对所有 int64 都是一一映射，不会产生冲突
+func Obfuscate(id int64, key uint64) int64 {
+	sign := uint64(id) & (1 << 63)
+	x := uint64(id) &^ (1 << 63)
+	// 64 位的平衡 Feistel 网络，结果落在 63 位之外时继续迭代（cycle walking），保证仍是 63 位上的置换
+	for {
+		x = feistelEncrypt(x, key)
+		if x>>63 == 0 {
+			return int64(sign | x)
+		}
+	}
+}
+
+// Deobfuscate 还原 Obfuscate 的结果，key 必须与混淆时相同
+func Deobfuscate(id int64, key uint64) int64 {
+	sign := uint64(id) & (1 << 63)
+	x := uint64(id) &^ (1 << 63)
+	for {
+		x = feistelDecrypt(x, key)
+		if x>>63 == 0 {
+			return int64(sign | x)
+		}
+	}
+}
+
+func feistelEncrypt(x, key uint64) uint64 {
+	l, r := uint32(x>>32), uint32(x)
+	for i := 0; i < feistelRounds; i++ {
+		l, r = r, l^feistelRound(r, key, i)
+	}
+	return uint64(l)<<32 | uint64(r)
+}
+
+func feistelDecrypt(x, key uint64) uint64 {
+	l, r := uint32(x>>32), uint32(x)
+	for i := feistelRounds - 1; i >= 0; i-- {
+		l, r = r^feistelRound(l, key, i), l
+	}
+	return uint64(l)<<32 | uint64(r)
+}
+
+// feistelRound 轮函数，基于 splitmix64 的混合步骤
+func feistelRound(r uint32, key uint64, round int) uint32 {
+	z := uint64(r) ^ key ^ uint64(round+1)*0x9E3779B97F4A7C15
+	z = (z ^ z>>30) * 0xBF58476D1CE4E5B9
+	z = (z ^ z>>27) * 0x94D049BB133111EB
+	z ^= z >> 31
+	return uint32(z)
+}
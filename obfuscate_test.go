@@ -0,0 +1,44 @@
+package snowflake_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestObfuscate(t *testing.T) {
+	const key = 0x5DEECE66D
+
+	sf := snowflake.NewWith(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), 1, 1)
+	ids := []int64{0, 1, 2, math.MaxInt64, -1, math.MinInt64}
+	for i := 0; i < 1000; i++ {
+		ids = append(ids, sf.MustNextID())
+	}
+
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		got := snowflake.Obfuscate(id, key)
+		if (got < 0) != (id < 0) {
+			t.Errorf("Obfuscate(%d) = %d, sign changed", id, got)
+		}
+		if seen[got] {
+			t.Errorf("Obfuscate(%d) = %d collides", id, got)
+		}
+		seen[got] = true
+
+		if back := snowflake.Deobfuscate(got, key); back != id {
+			t.Errorf("Deobfuscate(Obfuscate(%d)) = %d", id, back)
+		}
+	}
+
+	// 相邻的 ID 混淆后不应仍然相邻
+	a, b := snowflake.Obfuscate(ids[6], key), snowflake.Obfuscate(ids[7], key)
+	if d := a - b; d > -1000 && d < 1000 {
+		t.Errorf("Obfuscate of adjacent ids = %d, %d, want scrambled", a, b)
+	}
+	if snowflake.Obfuscate(ids[6], key) == snowflake.Obfuscate(ids[6], key+1) {
+		t.Error("Obfuscate with different keys should give different results")
+	}
+}
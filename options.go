@@ -0,0 +1,315 @@
+package snowflake
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Option New 和 NewWithOptions 的配置项
+type Option func(*options)
+
+type options struct {
+	startTime time.Time
+	config    Config
+
+	dataCenterID    uint8
+	hasDataCenterID bool
+	workerID        uint8
+	hasWorkerID     bool
+	provider        MachineIDProvider
+
+	clock                Clock
+	maxBackwardTolerance time.Duration
+	waitStrategy         WaitStrategy
+	backwardsPolicy      ClockBackwardsPolicy
+	panicHandler         func(err error)
+	maxDriftMs           int
+	onSequenceExhausted  func(ts int64)
+	waitFunc             func(lastTs int64) int64
+
+	randomSequenceStart bool
+	sequenceSeed        int64
+	relaxed             bool
+	sequenceOffset      int16
+	rateTracking        bool
+
+	logger Logger
+}
+
+// Logger 接收生成器的告警，*log.Logger 实现了该接口
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// WithStartTime 设置开始时间，默认为 DefaultEpoch
+func WithStartTime(startTime time.Time) Option {
+	return func(o *options) {
+		o.startTime = startTime
+	}
+}
+
+// WithConfig 设置位分配，默认为 DefaultConfig
+func WithConfig(cfg Config) Option {
+	return func(o *options) {
+		o.config = cfg
+	}
+}
+
+// WithDataCenterID 设置 dataCenterID，未设置时从 MachineIDProvider 获取
+func WithDataCenterID(dataCenterID uint8) Option {
+	return func(o *options) {
+		o.dataCenterID, o.hasDataCenterID = dataCenterID, true
+	}
+}
+
+// WithWorkerID 设置 workerID，未设置时从 MachineIDProvider 获取
+func WithWorkerID(workerID uint8) Option {
+	return func(o *options) {
+		o.workerID, o.hasWorkerID = workerID, true
+	}
+}
+
+// WithMachineIDProvider 设置获取 dataCenterID 和 workerID 的 MachineIDProvider，
+// 默认依次尝试 IPProvider 和 ProcessProvider
+// 同时设置了 WithDataCenterID 和 WithWorkerID 时不会使用
+func WithMachineIDProvider(p MachineIDProvider) Option {
+	return func(o *options) {
+		o.provider = p
+	}
+}
+
+// WithClock 设置时间源，默认使用系统时间
+func WithClock(c Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// WithMaxBackwardTolerance 设置可容忍的时钟回拨，同 SetMaxBackwardTolerance
+func WithMaxBackwardTolerance(d time.Duration) Option {
+	return func(o *options) {
+		o.maxBackwardTolerance = d
+	}
+}
+
+// WithWaitStrategy 设置等待时钟前进时的策略，同 SetWaitStrategy
+func WithWaitStrategy(ws WaitStrategy) Option {
+	return func(o *options) {
+		o.waitStrategy = ws
+	}
+}
+
+// WithClockBackwardsPolicy 设置时钟回拨超出可容忍范围时的处理方式，同 SetClockBackwardsPolicy
+func WithClockBackwardsPolicy(p ClockBackwardsPolicy) Option {
+	return func(o *options) {
+		o.backwardsPolicy = p
+	}
+}
+
+// WithPanicHandler 设置 BackwardsPanic 策略下时钟回拨时调用的函数，取代直接 panic，err 是 *ClockBackwardsError
+// fn 返回后本次生成返回 err；fn 可以自行 panic 或转换为框架的错误处理，它在持有锁时调用，不能再调用该生成器
+// 不设置时仍直接 panic
+func WithPanicHandler(fn func(err error)) Option {
+	return func(o *options) {
+		o.panicHandler = fn
+	}
+}
+
+// WithLogicalAdvance 当前毫秒内序号用完时，不等待时钟，而是在逻辑上把时间戳推进一毫秒继续生成，
+// 只要推进后的时间戳领先时钟不超过 maxDriftMs 毫秒；超过时仍等待时钟追上。领先范围内的时钟回拨也会被当作逻辑推进处理
+// 短时间的突发流量不必忙等，但这期间 ID 中的时间会略晚于实际生成时间（最多 maxDriftMs 毫秒）
+func WithLogicalAdvance(maxDriftMs int) Option {
+	return func(o *options) {
+		o.maxDriftMs = maxDriftMs
+	}
+}
+
+// WithOnSequenceExhausted 设置当前毫秒内序号用完时的回调，ts 是序号用完的那一毫秒的时间戳（毫秒，使用微秒时间戳时为微秒）
+// 回调在等到下一毫秒、生成 ID 并释放锁之后调用，因此回调中可以再调用生成器；回调会阻塞本次生成的返回，应尽快返回
+func WithOnSequenceExhausted(fn func(ts int64)) Option {
+	return func(o *options) {
+		o.onSequenceExhausted = fn
+	}
+}
+
+// WithWaitFunc 设置当前毫秒内序号用完时获取下一个时间戳的函数，取代内置的等待策略（见 WithWaitStrategy）和逻辑推进（见 WithLogicalAdvance）
+// lastTs 是序号用完的那个时间戳，单位同 WithOnSequenceExhausted；fn 应返回大于 lastTs 的时间戳，
+// 可以自行自旋、休眠，或不等待直接返回 lastTs+1，返回值不大于 lastTs 时退回内置的等待；
+// 返回值领先时钟时，下次生成会被当作时钟回拨，需要同时设置 WithLogicalAdvance
+// fn 在持有锁时调用，不会感知 NextIDContext 的 ctx，默认为 nil，即保持内置的行为
+func WithWaitFunc(fn func(lastTs int64) int64) Option {
+	return func(o *options) {
+		o.waitFunc = fn
+	}
+}
+
+// WithRandomSequenceStart 每个新毫秒的起始序号取 seed 决定的随机值，而不是 0，使低流量节点的 ID 更难被猜到
+// 起始序号只取序号空间的前一半，毫秒内仍严格递增，但每毫秒的容量会相应减少（至少为原来的一半）
+func WithRandomSequenceStart(seed int64) Option {
+	return func(o *options) {
+		o.randomSequenceStart, o.sequenceSeed = true, seed
+	}
+}
+
+// WithLogger 设置接收告警的 Logger，默认不输出任何内容
+// 目前只在创建时告警：机器 ID 是自动获取的（未同时指定 dataCenterID 和 workerID），且结果是 0, 0，
+// 这通常说明获取机器 ID 失败了，多台机器都使用 0, 0 会生成重复的 ID
+func WithLogger(l Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithSequenceOffset 每个新毫秒的起始序号为 offset，而不是 0，常用于让测试数据中不同节点的 ID 可预测地交错
+// offset 需要在序号空间内（默认 [0, 4095]），否则 NewWithOptions 返回错误；每毫秒的容量相应减少为 4096 - offset，
+// MaxIDsPerMillisecond 等返回的也是减少后的容量
+func WithSequenceOffset(offset int16) Option {
+	return func(o *options) {
+		o.sequenceOffset = offset
+	}
+}
+
+// WithRelaxedSequence 放宽唯一性换取吞吐量：NextID 和 NextIDContext 不再加锁，序号取自无锁的伪随机数，而不是严格递增
+// 同一节点同一毫秒内的 ID 可能重复：每毫秒生成 n 个 ID 时，出现重复的概率约为 n²/2^(SequenceBits+1)，
+// 默认 12 位序号下每毫秒 10 个 ID 约为 1%，100 个约为 70%；ID 也不再严格递增，只按毫秒有序
+// 这种模式同样不检查时钟回拨和序号用完，只适合能容忍少量重复 ID 的场景（如分析日志的埋点），
+// NextIDs、FillIDs 等批量接口不受影响，仍然加锁生成
+func WithRelaxedSequence() Option {
+	return func(o *options) {
+		o.relaxed = true
+	}
+}
+
+// WithRateTracking 开启 CurrentRate 的统计，每生成一个 ID 按 ID 中的时间戳更新一个计数，默认关闭
+func WithRateTracking() Option {
+	return func(o *options) {
+		o.rateTracking = true
+	}
+}
+
+// NewDefault 使用 DefaultEpoch 作为开始时间、依次通过 IP 地址和主机名获取机器 ID 创建 SnowFlake
+// 得到的 dataCenterID 和 workerID 是 0, 0 时视为获取失败，继续尝试下一种方式；
+// 与 New 不同，两者都获取不到时返回错误（都得到 0, 0 时为 ErrZeroMachineID），而不是退回进程内随机值或 0, 0
+// 与 NewWithOptions 一样会在进程内登记节点，同一进程内再次调用会因为节点已被使用而返回错误，直到之前的生成器调用 Close
+func NewDefault() (*SnowFlake, error) {
+	return newDefault(IPProvider{}, HostnameProvider{})
+}
+
+// newDefault 同 NewDefault，依次通过 providers 获取机器 ID
+func newDefault(providers ...MachineIDProvider) (*SnowFlake, error) {
+	chain := make(ChainProvider, len(providers))
+	for i, p := range providers {
+		chain[i] = nonZeroProvider{p}
+	}
+	return NewWithOptions(WithMachineIDProvider(chain))
+}
+
+// nonZeroProvider 把 MachineIDProvider 得到的 0, 0 当作错误，使 ChainProvider 回退到下一个
+type nonZeroProvider struct {
+	MachineIDProvider
+}
+
+// MachineID 实现 MachineIDProvider
+func (p nonZeroProvider) MachineID() (uint8, uint8, error) {
+	dataCenterID, workerID, err := p.MachineIDProvider.MachineID()
+	if err == nil && dataCenterID == 0 && workerID == 0 {
+		return 0, 0, ErrZeroMachineID
+	}
+	return dataCenterID, workerID, err
+}
+
+// NewWithOptions 根据 opts 创建 SnowFlake，默认值同 New
+// 与 New 不同，获取机器 ID 失败或 dataCenterID、workerID 超出位分配的范围时返回错误，
+// 开始时间晚于时间源的当前时间时返回 ErrFutureStartTime；
+// 同一进程内该节点已被未 Close 的生成器使用时也返回错误，见 NewWithStrict
+func NewWithOptions(opts ...Option) (*SnowFlake, error) {
+	return newWithOptions(true, opts...)
+}
+
+// newWithOptions strict 为 false 时保持 NewWith 的宽松行为：获取机器 ID 失败时使用 0, 0，超出范围的 ID 截掉高位
+func newWithOptions(strict bool, opts ...Option) (*SnowFlake, error) {
+	o := options{
+		startTime: DefaultEpoch,
+		config:    DefaultConfig,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if err := o.config.Validate(); err != nil {
+		return nil, err
+	}
+	l := newLayout(o.config)
+	if o.sequenceOffset < 0 || int64(o.sequenceOffset) > l.sequenceMask {
+		return nil, fmt.Errorf("snowflake: sequence offset %d out of range [0, %d]", o.sequenceOffset, l.sequenceMask)
+	}
+
+	if !o.hasDataCenterID || !o.hasWorkerID {
+		provider := o.provider
+		if provider == nil {
+			provider = defaultProvider
+		}
+
+		dataCenterID, workerID, err := provider.MachineID()
+		if err != nil && strict {
+			return nil, err
+		}
+		if !o.hasDataCenterID {
+			o.dataCenterID = dataCenterID
+		}
+		if !o.hasWorkerID {
+			o.workerID = workerID
+		}
+		if o.logger != nil && o.dataCenterID == 0 && o.workerID == 0 {
+			if err != nil {
+				o.logger.Printf("snowflake: WARNING: failed to get machine id (%v), using dataCenterID 0, workerID 0, ids may collide with other nodes", err)
+			} else {
+				o.logger.Printf("snowflake: WARNING: machine id resolved to dataCenterID 0, workerID 0, ids may collide with other nodes")
+			}
+		}
+	}
+
+	if strict {
+		if err := l.checkNode(o.dataCenterID, o.workerID); err != nil {
+			return nil, err
+		}
+	}
+
+	s := newWith(l, o.startTime, o.dataCenterID, o.workerID)
+	s.explicitNode = o.hasDataCenterID && o.hasWorkerID
+	if o.clock != nil {
+		s.clock = o.clock
+	}
+	if strict {
+		if now := s.clock.Now(); o.startTime.After(now) {
+			return nil, futureStartTimeError(o.startTime, now)
+		}
+	}
+	if strict {
+		if err := s.register(); err != nil {
+			return nil, err
+		}
+	}
+	s.maxBackwardTolerance = o.maxBackwardTolerance
+	s.waitStrategy = o.waitStrategy
+	s.backwardsPolicy = o.backwardsPolicy
+	s.panicHandler = o.panicHandler
+	if o.maxDriftMs > 0 {
+		s.maxLogicalDrift = int64(o.maxDriftMs) * int64(time.Millisecond/l.unit)
+	}
+	s.onSequenceExhausted = o.onSequenceExhausted
+	s.sequenceOffset = int64(o.sequenceOffset)
+	s.waitFunc = o.waitFunc
+	if o.rateTracking {
+		s.rate = &rateTracker{}
+	}
+	if o.relaxed {
+		s.relaxed, s.relaxedState = true, uint64(s.clock.Now().UnixNano())
+	}
+	if o.randomSequenceStart {
+		s.sequenceRand = rand.New(rand.NewSource(o.sequenceSeed))
+	}
+
+	return s, nil
+}
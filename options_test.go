@@ -0,0 +1,362 @@
+package snowflake_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	sf := snowflake.New(
+		snowflake.WithStartTime(testStartTime),
+		snowflake.WithDataCenterID(3),
+		snowflake.WithWorkerID(7),
+		snowflake.WithClock(newFakeClock(testNow)),
+	)
+	if sf.DataCenterID() != 3 || sf.WorkerID() != 7 {
+		t.Errorf("DataCenterID, WorkerID = %d, %d, want 3, 7", sf.DataCenterID(), sf.WorkerID())
+	}
+	if !sf.StartTime().Equal(testStartTime) {
+		t.Errorf("StartTime = %s, want %s", sf.StartTime(), testStartTime)
+	}
+	if got := sf.TimeOf(sf.MustNextID()); !got.Equal(testNow) {
+		t.Errorf("TimeOf = %s, want %s", got, testNow)
+	}
+}
+
+func TestNewWithOptionsProvider(t *testing.T) {
+	// 只设置 workerID 时，dataCenterID 来自 MachineIDProvider
+	sf, err := snowflake.NewWithOptions(
+		snowflake.WithMachineIDProvider(stubProvider{dataCenterID: 5, workerID: 6}),
+		snowflake.WithWorkerID(9),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	if sf.DataCenterID() != 5 || sf.WorkerID() != 9 {
+		t.Errorf("DataCenterID, WorkerID = %d, %d, want 5, 9", sf.DataCenterID(), sf.WorkerID())
+	}
+
+	wantErr := errors.New("no lease")
+	if _, err := snowflake.NewWithOptions(snowflake.WithMachineIDProvider(stubProvider{err: wantErr})); err != wantErr {
+		t.Errorf("err = %v, want %v", err, wantErr)
+	}
+	// New 保持宽松行为，获取失败时使用 0, 0
+	if sf := snowflake.New(snowflake.WithMachineIDProvider(stubProvider{err: wantErr})); sf.NodeID() != 0 {
+		t.Errorf("NodeID = %d, want 0", sf.NodeID())
+	}
+}
+
+func TestNewWithOptionsInvalid(t *testing.T) {
+	if _, err := snowflake.NewWithOptions(snowflake.WithDataCenterID(32), snowflake.WithWorkerID(1)); err == nil {
+		t.Error("dataCenterID 32 should be rejected")
+	}
+	if _, err := snowflake.NewWithOptions(snowflake.WithConfig(snowflake.Config{})); err == nil {
+		t.Error("invalid Config should be rejected")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("New with invalid Config should panic")
+		}
+	}()
+	snowflake.New(snowflake.WithConfig(snowflake.Config{}), snowflake.WithMaxBackwardTolerance(time.Millisecond))
+}
+
+func TestWithOnSequenceExhausted(t *testing.T) {
+	var (
+		sf    *snowflake.SnowFlake
+		fired []int64
+	)
+
+	times := append(repeatTime(testNow, 4097), testNow.Add(time.Millisecond))
+	sf = snowflake.New(
+		snowflake.WithStartTime(testStartTime),
+		snowflake.WithDataCenterID(1),
+		snowflake.WithWorkerID(2),
+		snowflake.WithClock(newFakeClock(times...)),
+		snowflake.WithOnSequenceExhausted(func(ts int64) {
+			fired = append(fired, ts)
+			// 回调在锁外调用，再次调用生成器不会死锁
+			sf.RemainingInMillis()
+		}),
+	)
+
+	for i := 0; i < 4096; i++ {
+		sf.MustNextID()
+	}
+	if len(fired) != 0 {
+		t.Fatalf("callback fired %d times before the sequence was exhausted", len(fired))
+	}
+
+	sf.MustNextID()
+	if len(fired) != 1 {
+		t.Fatalf("callback fired %d times, want 1", len(fired))
+	}
+	if want := testNow.UnixNano() / 1e6; fired[0] != want {
+		t.Errorf("ts = %d, want %d", fired[0], want)
+	}
+}
+
+func TestWithWaitFunc(t *testing.T) {
+	var calls []int64
+	newSF := func(ret func(lastTs int64) int64, times ...time.Time) *snowflake.SnowFlake {
+		calls = nil
+		return snowflake.New(
+			snowflake.WithStartTime(testStartTime),
+			snowflake.WithDataCenterID(1),
+			snowflake.WithWorkerID(2),
+			snowflake.WithClock(newFakeClock(times...)),
+			snowflake.WithLogicalAdvance(1),
+			snowflake.WithWaitFunc(func(lastTs int64) int64 {
+				calls = append(calls, lastTs)
+				return ret(lastTs)
+			}),
+		)
+	}
+	nowMs := testNow.UnixNano() / 1e6
+
+	// 不等待，直接推进到下一毫秒
+	sf := newSF(func(lastTs int64) int64 { return lastTs + 1 }, testNow)
+	for i := 0; i < 4096; i++ {
+		sf.MustNextID()
+	}
+	if len(calls) != 0 {
+		t.Fatalf("wait func called %d times before the sequence was exhausted", len(calls))
+	}
+	elapsedMs, _, _, sequence := snowflake.ParseID(sf.MustNextID(), testStartTime)
+	if len(calls) != 1 || calls[0] != nowMs {
+		t.Fatalf("wait func calls = %v, want [%d]", calls, nowMs)
+	}
+	if want := nowMs + 1 - testStartTime.UnixNano()/1e6; elapsedMs != want || sequence != 0 {
+		t.Errorf("id after exhaustion = elapsed %d sequence %d, want %d, 0", elapsedMs, sequence, want)
+	}
+
+	// 返回的时间戳没有前进时退回内置的等待
+	sf = newSF(func(lastTs int64) int64 { return lastTs }, append(repeatTime(testNow, 4097), testNow.Add(2*time.Millisecond))...)
+	for i := 0; i < 4096; i++ {
+		sf.MustNextID()
+	}
+	if elapsedMs, _, _, _ := snowflake.ParseID(sf.MustNextID(), testStartTime); elapsedMs != nowMs+2-testStartTime.UnixNano()/1e6 {
+		t.Errorf("elapsed = %d, want the clock after the built-in wait", elapsedMs)
+	}
+	if len(calls) != 1 {
+		t.Errorf("wait func called %d times, want 1", len(calls))
+	}
+}
+
+func TestWithSequenceOffset(t *testing.T) {
+	newSF := func(offset int16, times ...time.Time) (*snowflake.SnowFlake, error) {
+		return snowflake.NewWithOptions(
+			snowflake.WithStartTime(testStartTime),
+			snowflake.WithDataCenterID(1),
+			snowflake.WithWorkerID(2),
+			snowflake.WithClock(newFakeClock(times...)),
+			snowflake.WithSequenceOffset(offset),
+		)
+	}
+
+	// 每毫秒从 4000 开始，只有 96 个序号，第 97 个等到下一毫秒
+	times := append(repeatTime(testNow, 98), testNow.Add(time.Millisecond))
+	sf, err := newSF(4000, times...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	if got := sf.MaxIDsPerMillisecond(); got != 96 {
+		t.Errorf("MaxIDsPerMillisecond() = %d, want 96", got)
+	}
+	for i := 0; i < 96; i++ {
+		if seq := sf.Decompose(sf.MustNextID()).Sequence; seq != int64(4000+i) {
+			t.Fatalf("id %d has sequence %d, want %d", i, seq, 4000+i)
+		}
+	}
+	p := sf.Decompose(sf.MustNextID())
+	if p.Sequence != 4000 || !p.Time.Equal(testNow.Add(time.Millisecond)) {
+		t.Errorf("id after exhaustion = %s, want sequence 4000 in the next millisecond", p)
+	}
+
+	for _, offset := range []int16{-1, 4096} {
+		if _, err := newSF(offset, testNow); err == nil {
+			t.Errorf("WithSequenceOffset(%d) should return an error", offset)
+		}
+	}
+}
+
+func TestWithRandomSequenceStart(t *testing.T) {
+	// 前 10 毫秒各生成一个 ID，之后在同一毫秒内生成到序号用完
+	var times []time.Time
+	for i := 0; i < 10; i++ {
+		times = append(times, testNow.Add(time.Duration(i)*time.Millisecond))
+	}
+	last := testNow.Add(10 * time.Millisecond)
+	times = append(times, repeatTime(last, 4096)...)
+	times = append(times, last.Add(time.Millisecond))
+
+	sf, err := snowflake.NewWithOptions(
+		snowflake.WithStartTime(testStartTime),
+		snowflake.WithDataCenterID(1),
+		snowflake.WithWorkerID(2),
+		snowflake.WithClock(newFakeClock(append([]time.Time{testNow}, times...)...)),
+		snowflake.WithRandomSequenceStart(42),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+
+	var nonZero int
+	var prev int64
+	for i := 0; i < 10; i++ {
+		id := sf.MustNextID()
+		_, _, _, seq := snowflake.ParseID(id, testStartTime)
+		if seq >= 2048 {
+			t.Errorf("first sequence = %d, want < 2048", seq)
+		}
+		if seq != 0 {
+			nonZero++
+		}
+		prev = id
+	}
+	if nonZero == 0 {
+		t.Error("all first sequences are 0, want random")
+	}
+
+	// 毫秒内严格递增，序号到最大值后进入下一毫秒
+	for i := 0; i < 4096; i++ {
+		id := sf.MustNextID()
+		if id <= prev {
+			t.Fatalf("id %d is not greater than previous %d", id, prev)
+		}
+		prev = id
+	}
+	if got := sf.TimeOf(prev); !got.After(last) {
+		t.Errorf("TimeOf(last id) = %s, want after %s", got, last)
+	}
+}
+
+func TestWithLogicalAdvance(t *testing.T) {
+	// 时钟一直停在 testNow
+	sf, err := snowflake.NewWithOptions(
+		snowflake.WithStartTime(testStartTime),
+		snowflake.WithDataCenterID(1),
+		snowflake.WithWorkerID(2),
+		snowflake.WithClock(newFakeClock(testNow)),
+		snowflake.WithLogicalAdvance(2),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+
+	// 不等待时钟，逻辑上推进到 testNow+2ms，共 3 毫秒的序号
+	var prev int64
+	for i := 0; i < 3*4096; i++ {
+		id := sf.MustNextID()
+		if id <= prev {
+			t.Fatalf("id %d is not greater than previous %d", id, prev)
+		}
+		prev = id
+	}
+	if got, want := sf.TimeOf(prev), testNow.Add(2*time.Millisecond); !got.Equal(want) {
+		t.Errorf("TimeOf(last id) = %s, want %s", got, want)
+	}
+
+	// 超出最大领先范围后退回等待时钟，这里用已取消的 ctx 验证确实进入了等待
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := sf.NextIDContext(ctx); err != context.Canceled {
+		t.Errorf("NextIDContext() beyond max drift error = %v, want context.Canceled", err)
+	}
+}
+
+func TestNewDefault(t *testing.T) {
+	sf, err := snowflake.NewDefaultWith(stubProvider{dataCenterID: 3, workerID: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sf.StartTime(); !got.Equal(snowflake.DefaultEpoch) {
+		t.Errorf("StartTime = %s, want %s", got, snowflake.DefaultEpoch)
+	}
+	if sf.DataCenterID() != 3 || sf.WorkerID() != 9 {
+		t.Errorf("DataCenterID, WorkerID = %d, %d, want 3, 9", sf.DataCenterID(), sf.WorkerID())
+	}
+
+	// 节点已登记，Close 之前再次创建返回错误
+	if _, err := snowflake.NewDefaultWith(stubProvider{dataCenterID: 3, workerID: 9}); err == nil {
+		t.Error("NewDefault with a node in use succeeded, want error")
+	}
+	sf.Close()
+	sf, err = snowflake.NewDefaultWith(stubProvider{dataCenterID: 3, workerID: 9})
+	if err != nil {
+		t.Fatalf("NewDefault after Close: %v", err)
+	}
+	sf.Close()
+
+	wantErr := errors.New("no network")
+	if _, err := snowflake.NewDefaultWith(stubProvider{err: wantErr}); err != wantErr {
+		t.Errorf("err = %v, want %v", err, wantErr)
+	}
+
+	// 得到 0, 0 时回退到下一种方式
+	sf, err = snowflake.NewDefaultWith(stubProvider{}, stubProvider{dataCenterID: 5, workerID: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sf.DataCenterID() != 5 || sf.WorkerID() != 6 {
+		t.Errorf("DataCenterID, WorkerID = %d, %d, want 5, 6 from the next provider", sf.DataCenterID(), sf.WorkerID())
+	}
+	sf.Close()
+
+	// 都得到 0, 0 时返回 ErrZeroMachineID，且不占用节点
+	if _, err := snowflake.NewDefaultWith(stubProvider{}, stubProvider{}); !errors.Is(err, snowflake.ErrZeroMachineID) {
+		t.Errorf("err = %v, want ErrZeroMachineID", err)
+	}
+	sf, err = snowflake.NewWithOptions(snowflake.WithDataCenterID(0), snowflake.WithWorkerID(0))
+	if err != nil {
+		t.Fatalf("node 0, 0 still registered: %v", err)
+	}
+	sf.Close()
+}
+
+// recordLogger 记录收到的日志，用于测试 WithLogger
+type recordLogger struct {
+	lines []string
+}
+
+func (l *recordLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func TestWithLogger(t *testing.T) {
+	// 自动获取的机器 ID 是 0, 0 时告警，获取失败时带上原因
+	logger := &recordLogger{}
+	snowflake.New(snowflake.WithLogger(logger), snowflake.WithMachineIDProvider(stubProvider{err: errors.New("no network")}))
+	if len(logger.lines) != 1 || !strings.Contains(logger.lines[0], "no network") {
+		t.Errorf("logged %q, want one warning mentioning the provider error", logger.lines)
+	}
+
+	logger = &recordLogger{}
+	sf, err := snowflake.NewWithOptions(snowflake.WithLogger(logger), snowflake.WithMachineIDProvider(stubProvider{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf.Close()
+	if len(logger.lines) != 1 || !strings.Contains(logger.lines[0], "dataCenterID 0, workerID 0") {
+		t.Errorf("logged %q, want one warning about dataCenterID 0, workerID 0", logger.lines)
+	}
+
+	// 非 0, 0 或显式指定的 0, 0 不告警
+	logger = &recordLogger{}
+	snowflake.New(snowflake.WithLogger(logger), snowflake.WithMachineIDProvider(stubProvider{dataCenterID: 1}))
+	snowflake.New(snowflake.WithLogger(logger), snowflake.WithDataCenterID(0), snowflake.WithWorkerID(0))
+	if len(logger.lines) != 0 {
+		t.Errorf("logged %q, want nothing", logger.lines)
+	}
+}
@@ -0,0 +1,209 @@
+package snowflake
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ParseID 将默认位分配的 SnowFlake 生成的 ID 拆解为各组成部分
+// elapsedMs 是相对 startTime 的毫秒数，startTime 需要与生成该 ID 的 SnowFlake 一致，
+// startTime.Add(time.Duration(elapsedMs) * time.Millisecond) 即为 ID 的生成时间
+func ParseID(id int64, startTime time.Time) (elapsedMs int64, dataCenterID, workerID uint8, sequence int16) {
+	elapsedMs, dataCenterID, workerID, seq := defaultLayout.parse(id)
+	// 默认位分配的序号只有 12 位，int16 足够
+	return elapsedMs, dataCenterID, workerID, int16(seq)
+}
+
+// parse 按位分配拆解 ID，使用无符号右移，uint64 位分配的 ID 转换为 int64 后同样适用
+func (l layout) parse(id int64) (elapsedMs int64, dataCenterID, workerID uint8, sequence int64) {
+	u := uint64(id)
+	elapsedMs = int64(u >> l.timestampLeftShift)
+	dataCenterID = uint8(int64(u>>l.dataCenterLeftShift) & l.dataCenterMask)
+	workerID = uint8(int64(u>>l.workerLeftShift) & l.workerMask)
+	sequence = int64(u>>l.sequenceLeftShift) & l.sequenceMask
+	return
+}
+
+// TimeOf 返回 ID 的生成时间（UTC），精度为位分配的时间单位（默认毫秒）
+// 只适用于本 SnowFlake（相同 startTime）生成的 ID，传入其他配置的生成器生成的 ID 会得到错误的时间；
+// NextUID 生成的 ID 转换为 int64 后传入即可
+func (s *SnowFlake) TimeOf(id int64) time.Time {
+	elapsed, _, _, _ := s.layout.parse(id)
+	d, _ := s.layout.duration(elapsed)
+	return s.startTime.Add(d)
+}
+
+// duration 将时间戳差值换算为 time.Duration，超出 time.Duration 的范围（约 292 年，如毫秒时间戳超过 43 位）时
+// 返回 time.Duration 的最大值，ok 为 false
+func (l layout) duration(elapsed int64) (d time.Duration, ok bool) {
+	if elapsed > math.MaxInt64/int64(l.unit) {
+		return time.Duration(math.MaxInt64), false
+	}
+	return time.Duration(elapsed) * l.unit, true
+}
+
+// Parts ID 拆解后的各组成部分
+type Parts struct {
+	// ID 的生成时间（UTC）
+	Time time.Time
+	// 相对开始时间的时间戳差值，单位为位分配的时间单位（默认毫秒）
+	ElapsedMs    int64
+	DataCenterID uint8
+	WorkerID     uint8
+	Sequence     int64
+	// 标签，位分配没有标签位（Config.TagBits 为 0）时总是 0
+	Tag uint8
+}
+
+// String 返回便于阅读的形式，如 2024-01-02T03:04:05.678Z dc=3 worker=7 seq=42，标签不为 0 时在末尾加上 tag=1
+func (p Parts) String() string {
+	s := fmt.Sprintf("%s dc=%d worker=%d seq=%d", formatTime(p.Time), p.DataCenterID, p.WorkerID, p.Sequence)
+	if p.Tag != 0 {
+		s += fmt.Sprintf(" tag=%d", p.Tag)
+	}
+	return s
+}
+
+// formatTime 按 RFC3339 格式化 ID 的时间，带 3 位小数，有亚毫秒精度时带 6 位小数
+func formatTime(t time.Time) string {
+	layout := "2006-01-02T15:04:05.000Z07:00"
+	if t.Nanosecond()%int(time.Millisecond) != 0 {
+		layout = "2006-01-02T15:04:05.000000Z07:00"
+	}
+	return t.Format(layout)
+}
+
+// Decompose 按本 SnowFlake 的开始时间和位分配拆解 ID
+func (s *SnowFlake) Decompose(id int64) Parts {
+	return s.layout.decompose(id, s.startTime)
+}
+
+// ParseIDWithEpoch 按给定的开始时间（Unix 毫秒）和位分配拆解 ID，用于解析其他服务生成的、开始时间不同的 ID
+// 不检查符号位，也不校验 ID 的时间是否合理；layout 需要是合法的位分配（见 Config.Validate）
+func ParseIDWithEpoch(id int64, epochMs int64, layout Config) Parts {
+	return newLayout(layout).decompose(id, time.UnixMilli(epochMs).UTC())
+}
+
+// GuessResolution 在不确定 ID 的时间戳是毫秒还是微秒时，按默认位分配的左移位数分别以毫秒和微秒解码时间戳，
+// 返回看起来合理的那种：时间不早于开始时间（Unix 毫秒 epochMs），也不晚于当前时间一分钟以上；
+// 两种都合理时选离当前时间更近的，都不合理时 ok 为 false
+// 这只是排查问题时的启发式判断，历史较短的开始时间下两种解释可能都合理，结果不一定正确
+func GuessResolution(id int64, epochMs int64) (unit time.Duration, t time.Time, ok bool) {
+	if id < 0 {
+		return 0, time.Time{}, false
+	}
+
+	epoch := time.UnixMilli(epochMs).UTC()
+	now := time.Now()
+	elapsed := id >> defaultLayout.timestampLeftShift
+
+	for _, u := range []time.Duration{time.Millisecond, time.Microsecond} {
+		candidate := epoch.Add(time.Duration(elapsed) * u)
+		if candidate.Before(epoch) || candidate.After(now.Add(validFutureSkew)) {
+			continue
+		}
+		if !ok || absDuration(now.Sub(candidate)) < absDuration(now.Sub(t)) {
+			unit, t, ok = u, candidate, true
+		}
+	}
+	return unit, t, ok
+}
+
+// absDuration 返回 d 的绝对值
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// decompose 按位分配和开始时间拆解 ID
+func (l layout) decompose(id int64, startTime time.Time) Parts {
+	elapsed, dataCenterID, workerID, sequence := l.parse(id)
+	d, _ := l.duration(elapsed)
+	return Parts{
+		Time:         startTime.Add(d),
+		ElapsedMs:    elapsed,
+		DataCenterID: dataCenterID,
+		WorkerID:     workerID,
+		Sequence:     sequence,
+		Tag:          uint8(id & l.tagMask),
+	}
+}
+
+// TimestampMsOf 返回 ID 中记录的 Unix 毫秒时间戳，等于 TimeOf(id).UnixMilli()，
+// 只做移位和加法，不拆解节点和序号，适合分页游标这类只关心时间的比较
+func (s *SnowFlake) TimestampMsOf(id int64) int64 {
+	elapsed := int64(uint64(id) >> s.layout.timestampLeftShift)
+	if s.layout.unit == time.Millisecond {
+		return s.startTime.UnixNano()/1e6 + elapsed
+	}
+	return (s.startTime.UnixNano()/int64(s.layout.unit) + elapsed) * int64(s.layout.unit) / 1e6
+}
+
+// NodeOf 返回 ID 中的节点 ID，即 dataCenterID 左移 WorkerBits 位后与 workerID 合并的值（默认位分配下为 10 位，含义同 NewWithNode），
+// 与 Decompose 拆解出的 DataCenterID、WorkerID 一致；只适用于本 SnowFlake 的位分配生成的 ID
+func (s *SnowFlake) NodeOf(id int64) uint16 {
+	_, dataCenterID, workerID, _ := s.layout.parse(id)
+	return s.layout.joinNode(dataCenterID, workerID)
+}
+
+// ElapsedOf 返回 ID 中记录的相对开始时间（Epoch）的时长，精度为位分配的时间单位（默认毫秒）
+func (s *SnowFlake) ElapsedOf(id int64) time.Duration {
+	elapsed, _, _, _ := s.layout.parse(id)
+	d, _ := s.layout.duration(elapsed)
+	return d
+}
+
+// AgeOf 返回 ID 生成至今的时长，当前时间取自生成器的时钟
+func (s *SnowFlake) AgeOf(id int64) time.Duration {
+	return s.clock.Now().Sub(s.TimeOf(id))
+}
+
+// validFutureSkew IsValid 允许 ID 的时间晚于当前时间的范围，容纳节点之间的时钟偏差
+const validFutureSkew = time.Minute
+
+// IsValid 粗略检查 id 是否可能是本 SnowFlake 生成的：不能是负数，时间不能早于开始时间，
+// 也不能晚于当前时间太多（超过一分钟加上可容忍的时钟回拨），时间戳差值不能超出 time.Duration 的范围
+// 这只是过滤明显伪造或损坏的 ID，不能保证 ID 真的由本生成器生成
+func (s *SnowFlake) IsValid(id int64) bool {
+	if id < 0 {
+		return false
+	}
+
+	s.mutex.Lock()
+	skew := validFutureSkew + s.maxBackwardTolerance
+	s.mutex.Unlock()
+
+	elapsed, _, _, _ := s.layout.parse(id)
+	d, ok := s.layout.duration(elapsed)
+	if !ok {
+		return false
+	}
+	t := s.startTime.Add(d)
+	return !t.Before(s.startTime) && !t.After(s.clock.Now().Add(skew))
+}
+
+// BelongsToEpoch 检查 id 是否可能按本 SnowFlake 的开始时间生成：时间戳不能为负数（符号位为 1，uint64 位分配除外），
+// 按开始时间解码出的时间也不能晚于当前时间太多（同 IsValid，超过一分钟加上可容忍的时钟回拨）
+// 开始时间更早的生成器生成的 ID 按本生成器的开始时间解码会落在未来，借此在合并不同开始时间的数据时找出不属于本生成器的 ID；
+// 开始时间更晚的生成器生成的 ID 解码后仍是过去的时间，无法区分
+// 与 IsValid 不同，uint64 位分配下符号位为 1 的 ID 也可能合法
+func (s *SnowFlake) BelongsToEpoch(id int64) bool {
+	if !s.layout.config.Unsigned && id < 0 {
+		return false
+	}
+
+	s.mutex.Lock()
+	skew := validFutureSkew + s.maxBackwardTolerance
+	s.mutex.Unlock()
+
+	elapsed, _, _, _ := s.layout.parse(id)
+	// 超出 time.Duration 的范围时肯定晚于当前时间
+	d, ok := s.layout.duration(elapsed)
+	if !ok {
+		return false
+	}
+	return !s.startTime.Add(d).After(s.clock.Now().Add(skew))
+}
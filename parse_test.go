@@ -0,0 +1,299 @@
+package snowflake_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestParseID(t *testing.T) {
+	startTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	sf := snowflake.NewWith(startTime, 3, 7)
+
+	before := time.Now().UTC().UnixNano()/1e6 - startTime.UnixNano()/1e6
+	id := sf.MustNextID()
+	after := time.Now().UTC().UnixNano()/1e6 - startTime.UnixNano()/1e6
+
+	elapsedMs, dataCenterID, workerID, sequence := snowflake.ParseID(id, startTime)
+	if elapsedMs < before || elapsedMs > after {
+		t.Errorf("elapsedMs = %d, want in [%d, %d]", elapsedMs, before, after)
+	}
+	if dataCenterID != 3 {
+		t.Errorf("dataCenterID = %d, want 3", dataCenterID)
+	}
+	if workerID != 7 {
+		t.Errorf("workerID = %d, want 7", workerID)
+	}
+	if sequence != 0 {
+		t.Errorf("sequence = %d, want 0", sequence)
+	}
+}
+
+func TestTimeOf(t *testing.T) {
+	startTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	sf := snowflake.NewWith(startTime, 1, 1)
+
+	before := time.Now().UTC().Truncate(time.Millisecond)
+	id := sf.MustNextID()
+	after := time.Now().UTC()
+
+	got := sf.TimeOf(id)
+	if got.Before(before) || got.After(after) {
+		t.Errorf("TimeOf = %s, want in [%s, %s]", got, before, after)
+	}
+	if got.Location() != time.UTC {
+		t.Errorf("TimeOf location = %s, want UTC", got.Location())
+	}
+}
+
+func TestParseIDWithEpoch(t *testing.T) {
+	// 其他服务使用不同的开始时间和位分配
+	cfg := snowflake.Config{TimestampBits: 41, DataCenterBits: 3, WorkerBits: 7, SequenceBits: 12}
+	epoch := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	foreign, err := snowflake.NewWithConfig(cfg, epoch, 5, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := foreign.MustNextID()
+
+	p := snowflake.ParseIDWithEpoch(id, epoch.UnixNano()/1e6, cfg)
+	if want := foreign.Decompose(id); p != want {
+		t.Errorf("ParseIDWithEpoch = %+v, want %+v", p, want)
+	}
+	if p.DataCenterID != 5 || p.WorkerID != 100 {
+		t.Errorf("DataCenterID, WorkerID = %d, %d, want 5, 100", p.DataCenterID, p.WorkerID)
+	}
+	if time.Since(p.Time) > time.Second {
+		t.Errorf("Time = %s, want close to now", p.Time)
+	}
+}
+
+func TestNextIDWithTime(t *testing.T) {
+	now := testNow.Add(123456789 * time.Nanosecond)
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(now), 1, 1)
+
+	id, created, err := sf.NextIDWithTime()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created.Equal(sf.TimeOf(id)) {
+		t.Errorf("NextIDWithTime time = %s, want TimeOf = %s", created, sf.TimeOf(id))
+	}
+	if want := now.Truncate(time.Millisecond); !created.Equal(want) {
+		t.Errorf("NextIDWithTime time = %s, want %s", created, want)
+	}
+
+	sf.Close()
+	if _, created, err := sf.NextIDWithTime(); err != snowflake.ErrClosed || !created.IsZero() {
+		t.Errorf("NextIDWithTime after Close = %s, %v, want zero time and ErrClosed", created, err)
+	}
+}
+
+func TestDecompose(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 678000000, time.UTC)
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(now), 3, 7)
+
+	var id int64
+	for i := 0; i <= 42; i++ {
+		id = sf.MustNextID()
+	}
+
+	p := sf.Decompose(id)
+	if !p.Time.Equal(now) || p.DataCenterID != 3 || p.WorkerID != 7 || p.Sequence != 42 {
+		t.Errorf("Decompose = %+v, want time %s dc 3 worker 7 seq 42", p, now)
+	}
+	if want := now.Sub(testStartTime).Milliseconds(); p.ElapsedMs != want {
+		t.Errorf("ElapsedMs = %d, want %d", p.ElapsedMs, want)
+	}
+	if got, want := p.String(), "2024-01-02T03:04:05.678Z dc=3 worker=7 seq=42"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestTimestampMsOf(t *testing.T) {
+	now := testNow.Add(123456789 * time.Nanosecond)
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(now), 1, 1)
+	id := sf.MustNextID()
+	if got, want := sf.TimestampMsOf(id), sf.TimeOf(id).UnixNano()/1e6; got != want {
+		t.Errorf("TimestampMsOf = %d, want %d", got, want)
+	}
+
+	cfg := snowflake.Config{TimestampBits: 51, DataCenterBits: 3, WorkerBits: 3, SequenceBits: 6, Unit: snowflake.Microsecond}
+	sf, err := snowflake.NewWithConfig(cfg, testStartTime.Add(700*time.Microsecond), 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id = sf.MustNextID()
+	if got, want := sf.TimestampMsOf(id), sf.TimeOf(id).UnixNano()/1e6; got != want {
+		t.Errorf("TimestampMsOf with microsecond unit = %d, want %d", got, want)
+	}
+}
+
+func BenchmarkTimestampMsOf(b *testing.B) {
+	sf := snowflake.NewWith(testStartTime, 1, 1)
+	id := sf.MustNextID()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sf.TimestampMsOf(id)
+	}
+}
+
+func TestAgeOf(t *testing.T) {
+	clock := newFakeClock(testNow, testNow.Add(90*time.Second))
+	sf := snowflake.NewWithClock(testStartTime, clock, 1, 1)
+
+	id := sf.MustNextID()
+	if got, want := sf.ElapsedOf(id), testNow.Sub(testStartTime); got != want {
+		t.Errorf("ElapsedOf = %s, want %s", got, want)
+	}
+	if got := sf.AgeOf(id); got != 90*time.Second {
+		t.Errorf("AgeOf = %s, want 1m30s", got)
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(testNow), 1, 2)
+
+	id := sf.MustNextID()
+	if !sf.IsValid(id) {
+		t.Errorf("IsValid(%d) = false, want true", id)
+	}
+	if !sf.IsValid(0) {
+		t.Error("IsValid(0) = false, want true")
+	}
+
+	if sf.IsValid(-id) {
+		t.Errorf("IsValid(%d) = true, want false", -id)
+	}
+
+	future := testNow.Add(time.Hour).Sub(testStartTime).Milliseconds() << 22
+	if sf.IsValid(future) {
+		t.Error("IsValid should reject ids an hour in the future")
+	}
+	nearFuture := testNow.Add(time.Second).Sub(testStartTime).Milliseconds() << 22
+	if !sf.IsValid(nearFuture) {
+		t.Error("IsValid should accept ids within the allowed clock skew")
+	}
+}
+
+func TestIsValidWideTimestamp(t *testing.T) {
+	cfg := snowflake.Config{TimestampBits: 55, DataCenterBits: 2, WorkerBits: 2, SequenceBits: 4}
+	sf, err := snowflake.NewWithConfig(cfg, testStartTime, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 换算为 time.Duration 时溢出，回绕到开始时间之后一秒多
+	const elapsed = 18446744074709
+	forged := int64(elapsed) << 8
+	if sf.IsValid(forged) {
+		t.Errorf("IsValid(%d) = true, want false", forged)
+	}
+	if sf.BelongsToEpoch(forged) {
+		t.Errorf("BelongsToEpoch(%d) = true, want false", forged)
+	}
+	if got := sf.ElapsedOf(forged); got != time.Duration(math.MaxInt64) {
+		t.Errorf("ElapsedOf = %s, want %s", got, time.Duration(math.MaxInt64))
+	}
+	if got, want := sf.TimeOf(forged), testStartTime.Add(time.Duration(math.MaxInt64)); !got.Equal(want) {
+		t.Errorf("TimeOf = %s, want %s", got, want)
+	}
+	if got := sf.Decompose(forged).Time; !got.Equal(sf.TimeOf(forged)) {
+		t.Errorf("Decompose.Time = %s, want %s", got, sf.TimeOf(forged))
+	}
+}
+
+func TestBelongsToEpoch(t *testing.T) {
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(testNow), 1, 2)
+
+	id := sf.MustNextID()
+	for _, id := range []int64{id, 0} {
+		if !sf.BelongsToEpoch(id) {
+			t.Errorf("BelongsToEpoch(%d) = false, want true", id)
+		}
+	}
+	for _, id := range []int64{-id, -1, math.MinInt64, math.MaxInt64} {
+		if sf.BelongsToEpoch(id) {
+			t.Errorf("BelongsToEpoch(%d) = true, want false", id)
+		}
+	}
+
+	// 开始时间早十年的生成器生成的 ID，按本生成器的开始时间解码会落在十年后
+	foreign := snowflake.NewWithClock(testStartTime.AddDate(-10, 0, 0), newFakeClock(testNow), 1, 2)
+	if foreignID := foreign.MustNextID(); sf.BelongsToEpoch(foreignID) {
+		t.Errorf("BelongsToEpoch(%d) from an earlier epoch = true, want false", foreignID)
+	}
+
+	// uint64 位分配的时间戳包含符号位，符号位为 1 的 ID 也可能合法
+	now := testStartTime.Add(time.Duration(1<<41)*time.Millisecond + time.Hour)
+	usf, err := snowflake.NewWithOptions(
+		snowflake.WithConfig(snowflake.DefaultUnsignedConfig),
+		snowflake.WithStartTime(testStartTime),
+		snowflake.WithClock(newFakeClock(now)),
+		snowflake.WithDataCenterID(1),
+		snowflake.WithWorkerID(2),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer usf.Close()
+	uid, err := usf.NextUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := int64(uid); id >= 0 || !usf.BelongsToEpoch(id) {
+		t.Errorf("BelongsToEpoch(%d) = false for an unsigned layout, want true", id)
+	}
+	if usf.BelongsToEpoch(-1) {
+		t.Error("BelongsToEpoch(-1) = true for an unsigned layout, want false")
+	}
+}
+
+func TestNodeOf(t *testing.T) {
+	sf := snowflake.NewWith(testStartTime, 19, 6)
+	id := sf.MustNextID()
+	if got := sf.NodeOf(id); got != 19<<5|6 || got != sf.NodeID() {
+		t.Errorf("NodeOf(%d) = %d, want %d", id, got, 19<<5|6)
+	}
+
+	// 自定义位分配下与 Decompose 一致
+	sf, err := snowflake.NewWithConfig(snowflake.Config{TimestampBits: 41, DataCenterBits: 3, WorkerBits: 7, SequenceBits: 12}, testStartTime, 5, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id = sf.MustNextID()
+	p := sf.Decompose(id)
+	if got, want := sf.NodeOf(id), uint16(p.DataCenterID)<<7|uint16(p.WorkerID); got != want {
+		t.Errorf("NodeOf(%d) = %d, want %d", id, got, want)
+	}
+}
+
+func TestGuessResolution(t *testing.T) {
+	epoch := time.Now().Add(-24 * time.Hour).Truncate(time.Millisecond)
+	created := time.Now().Add(-time.Hour)
+
+	// 毫秒时间戳：按微秒解释会落在开始时间之后不到 100 秒，比毫秒的解释离当前时间更远
+	msID := created.Sub(epoch).Milliseconds() << 22
+	unit, got, ok := snowflake.GuessResolution(msID, epoch.UnixMilli())
+	if !ok || unit != time.Millisecond || !got.Equal(created.Truncate(time.Millisecond)) {
+		t.Errorf("GuessResolution(ms id) = %s, %s, %v, want ms, %s", unit, got, ok, created.Truncate(time.Millisecond))
+	}
+
+	// 微秒时间戳：按毫秒解释会晚于当前时间很多
+	usID := created.Sub(epoch).Microseconds() << 22
+	unit, got, ok = snowflake.GuessResolution(usID, epoch.UnixMilli())
+	if !ok || unit != time.Microsecond || !got.Equal(created.Truncate(time.Microsecond)) {
+		t.Errorf("GuessResolution(µs id) = %s, %s, %v, want µs, %s", unit, got, ok, created.Truncate(time.Microsecond))
+	}
+
+	// 开始时间在将来或 ID 为负数时都不合理
+	if _, _, ok := snowflake.GuessResolution(msID, time.Now().Add(time.Hour).UnixMilli()); ok {
+		t.Error("GuessResolution with a future epoch should not be ok")
+	}
+	if _, _, ok := snowflake.GuessResolution(-msID, epoch.UnixMilli()); ok {
+		t.Error("GuessResolution with a negative id should not be ok")
+	}
+}
@@ -0,0 +1,55 @@
+package snowflake
+
+import "time"
+
+const (
+	// rateBucket CurrentRate 每个桶的时长
+	rateBucket = 100 * time.Millisecond
+	// rateBuckets 桶的个数，滑动窗口为 rateBucket * rateBuckets，即一秒
+	rateBuckets = 10
+)
+
+// rateTracker 按 rateBucket 分桶记录最近一秒生成的 ID 数，环形使用，调用方需持有锁
+type rateTracker struct {
+	// slots 每个桶对应的时间段（Unix 纳秒 / rateBucket），counts 是该时间段内生成的 ID 数
+	slots  [rateBuckets]int64
+	counts [rateBuckets]uint64
+}
+
+// record 记录生成了一个 ID，unixNano 是 ID 中的时间戳（Unix 纳秒）
+func (r *rateTracker) record(unixNano int64) {
+	slot := unixNano / int64(rateBucket)
+	i := slot % rateBuckets
+	if r.slots[i] != slot {
+		r.slots[i], r.counts[i] = slot, 0
+	}
+	r.counts[i]++
+}
+
+// rate 返回截至 now 的最近一秒内平均每秒生成的 ID 数
+func (r *rateTracker) rate(now time.Time) float64 {
+	slot := now.UnixNano() / int64(rateBucket)
+
+	var total uint64
+	for i := range r.slots {
+		if r.slots[i] > slot-rateBuckets && r.slots[i] <= slot {
+			total += r.counts[i]
+		}
+	}
+
+	// 窗口由之前的 rateBuckets-1 个完整的桶和当前桶已经过去的部分组成
+	window := time.Duration(rateBuckets-1)*rateBucket + time.Duration(now.UnixNano()-slot*int64(rateBucket))
+	return float64(total) / window.Seconds()
+}
+
+// CurrentRate 返回最近一秒内平均每秒生成的 ID 数，可以与 MaxIDsPerSecond 对比；未开启 WithRateTracking 时返回 0
+// 只统计 NextID、NextIDs 等加锁生成的 ID，WithRelaxedSequence 不加锁生成的 ID 不计入
+func (s *SnowFlake) CurrentRate() float64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.rate == nil {
+		return 0
+	}
+	return s.rate.rate(s.clock.Now())
+}
@@ -0,0 +1,42 @@
+package snowflake_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestCurrentRate(t *testing.T) {
+	if got := snowflake.NewWith(testStartTime, 1, 2).CurrentRate(); got != 0 {
+		t.Errorf("CurrentRate() without WithRateTracking = %v, want 0", got)
+	}
+
+	// 每 10ms 生成 5 个 ID，持续两秒，即每秒 500 个
+	var times []time.Time
+	for ms := 0; ms < 2000; ms += 10 {
+		times = append(times, repeatTime(testNow.Add(time.Duration(ms)*time.Millisecond), 5)...)
+	}
+	end := testNow.Add(1999 * time.Millisecond)
+	times = append(times, end)
+
+	sf, err := snowflake.NewWithOptions(
+		snowflake.WithStartTime(testStartTime),
+		snowflake.WithDataCenterID(1),
+		snowflake.WithWorkerID(2),
+		snowflake.WithClock(newFakeClock(append([]time.Time{testNow}, times...)...)),
+		snowflake.WithRateTracking(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+
+	for i := 0; i < len(times)-1; i++ {
+		sf.MustNextID()
+	}
+	if got := sf.CurrentRate(); math.Abs(got-500) > 10 {
+		t.Errorf("CurrentRate() = %v, want about 500", got)
+	}
+}
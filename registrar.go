@@ -0,0 +1,68 @@
+package snowflake
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Registrar 节点 ID 的分配器，保证同一时间不会有两个进程拿到相同的节点 ID
+// 可以基于 Redis、etcd 等实现带租约的分配，release 用于归还节点 ID
+type Registrar interface {
+	Acquire() (nodeID uint16, release func(), err error)
+}
+
+// NewWithRegistrar 给定开始时间，通过 r 获取节点 ID（含义同 NewWithNode）
+// 节点 ID 在 Close 时归还
+func NewWithRegistrar(startTime time.Time, r Registrar) (*SnowFlake, error) {
+	nodeID, release, err := r.Acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := NewWithNode(startTime, nodeID)
+	if err != nil {
+		if release != nil {
+			release()
+		}
+		return nil, err
+	}
+	s.release = release
+
+	return s, nil
+}
+
+// MemoryRegistrar 进程内的 Registrar，每次分配最小的空闲节点 ID，用于测试，并发安全
+type MemoryRegistrar struct {
+	mutex sync.Mutex
+	used  map[uint16]bool
+}
+
+// NewMemoryRegistrar 创建进程内的 Registrar
+func NewMemoryRegistrar() *MemoryRegistrar {
+	return &MemoryRegistrar{used: make(map[uint16]bool)}
+}
+
+// Acquire 实现 Registrar，1024 个节点 ID 都在使用时返回错误
+func (r *MemoryRegistrar) Acquire() (uint16, func(), error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for nodeID := uint16(0); nodeID < 1<<defaultLayout.nodeBits(); nodeID++ {
+		if r.used[nodeID] {
+			continue
+		}
+		r.used[nodeID] = true
+
+		var once sync.Once
+		return nodeID, func() {
+			once.Do(func() {
+				r.mutex.Lock()
+				delete(r.used, nodeID)
+				r.mutex.Unlock()
+			})
+		}, nil
+	}
+
+	return 0, nil, errors.New("snowflake: all node ids are in use")
+}
@@ -0,0 +1,52 @@
+package snowflake_test
+
+import (
+	"testing"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestNewWithRegistrar(t *testing.T) {
+	r := snowflake.NewMemoryRegistrar()
+
+	first, err := snowflake.NewWithRegistrar(testStartTime, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := snowflake.NewWithRegistrar(testStartTime, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+	if first.NodeID() == second.NodeID() {
+		t.Fatalf("both generators got node id %d", first.NodeID())
+	}
+
+	// 归还后节点 ID 可以被重新分配
+	nodeID := first.NodeID()
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+	first.Close()
+
+	third, err := snowflake.NewWithRegistrar(testStartTime, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer third.Close()
+	if third.NodeID() != nodeID {
+		t.Errorf("NodeID = %d, want released node id %d", third.NodeID(), nodeID)
+	}
+}
+
+func TestMemoryRegistrarExhausted(t *testing.T) {
+	r := snowflake.NewMemoryRegistrar()
+	for i := 0; i < 1024; i++ {
+		if _, _, err := r.Acquire(); err != nil {
+			t.Fatalf("Acquire #%d: %v", i, err)
+		}
+	}
+	if _, _, err := r.Acquire(); err == nil {
+		t.Error("Acquire should fail when all node ids are in use")
+	}
+}
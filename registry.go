@@ -0,0 +1,45 @@
+package snowflake
+
+import (
+	"fmt"
+	"sync"
+)
+
+// nodeKey 进程内注册的节点
+type nodeKey struct {
+	dataCenterID uint8
+	workerID     uint8
+}
+
+var (
+	registryMutex sync.Mutex
+	// liveNodes 通过严格的构造函数（NewWithStrict、NewWithNode、NewWithOptions）创建、还没有 Close 的生成器使用的节点
+	liveNodes = make(map[nodeKey]bool)
+)
+
+// register 在进程内登记 s 的节点，同一进程内已有未 Close 的生成器使用该节点时返回错误
+func (s *SnowFlake) register() error {
+	key := nodeKey{s.dataCenterID, s.workerID}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if liveNodes[key] {
+		return fmt.Errorf("snowflake: dataCenterID %d, workerID %d is already used by another generator in this process", s.dataCenterID, s.workerID)
+	}
+	liveNodes[key] = true
+	s.registered = true
+	return nil
+}
+
+// unregister 释放 register 登记的节点，调用方需持有 s.mutex
+func (s *SnowFlake) unregister() {
+	if !s.registered {
+		return
+	}
+	s.registered = false
+
+	registryMutex.Lock()
+	delete(liveNodes, nodeKey{s.dataCenterID, s.workerID})
+	registryMutex.Unlock()
+}
@@ -0,0 +1,31 @@
+package snowflake_test
+
+import (
+	"testing"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestDuplicateNodeInProcess(t *testing.T) {
+	first, err := snowflake.NewWithStrict(testStartTime, 5, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := snowflake.NewWithNode(testStartTime, snowflake.NodeID(5, 6)); err == nil {
+		t.Error("NewWithNode with a node in use should return an error")
+	}
+	if _, err := snowflake.NewWithOptions(snowflake.WithStartTime(testStartTime), snowflake.WithDataCenterID(5), snowflake.WithWorkerID(6)); err == nil {
+		t.Error("NewWithOptions with a node in use should return an error")
+	}
+	// 宽松的构造函数不做检查
+	snowflake.NewWith(testStartTime, 5, 6)
+
+	// Close 之后节点可以再次使用
+	first.Close()
+	second, err := snowflake.NewWithStrict(testStartTime, 5, 6)
+	if err != nil {
+		t.Fatalf("NewWithStrict after Close: %v", err)
+	}
+	second.Close()
+}
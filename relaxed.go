@@ -0,0 +1,22 @@
+package snowflake
+
+import "sync/atomic"
+
+// relaxedID WithRelaxedSequence 模式下不加锁生成 ID，序号取 splitmix64 伪随机数的低位
+func (s *SnowFlake) relaxedID() (int64, error) {
+	if s.isClosed() {
+		return 0, ErrClosed
+	}
+
+	z := atomic.AddUint64(&s.relaxedState, 0x9E3779B97F4A7C15)
+	z = (z ^ z>>30) * 0xBF58476D1CE4E5B9
+	z = (z ^ z>>27) * 0x94D049BB133111EB
+	z ^= z >> 31
+
+	id, err := s.compose(s.genTimestamp(), int64(z)&s.layout.sequenceMask)
+	if err != nil {
+		return 0, err
+	}
+	atomic.AddUint64(&s.counters.generated, 1)
+	return id, nil
+}
@@ -0,0 +1,77 @@
+package snowflake_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func newRelaxed(t testing.TB) *snowflake.SnowFlake {
+	sf, err := snowflake.NewWithOptions(
+		snowflake.WithStartTime(testStartTime),
+		snowflake.WithDataCenterID(3),
+		snowflake.WithWorkerID(4),
+		snowflake.WithRelaxedSequence(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sf
+}
+
+func TestWithRelaxedSequence(t *testing.T) {
+	sf := newRelaxed(t)
+	defer sf.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				p := sf.Decompose(sf.MustNextID())
+				if p.DataCenterID != 3 || p.WorkerID != 4 {
+					t.Errorf("Decompose = %+v, want dc 3 worker 4", p)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := sf.Stats().Generated; got != 8000 {
+		t.Errorf("Generated = %d, want 8000", got)
+	}
+
+	// 序号是随机的，而不是从 0 开始递增
+	sequences := make(map[int64]bool)
+	for i := 0; i < 100; i++ {
+		sequences[sf.Decompose(sf.MustNextID()).Sequence] = true
+	}
+	if len(sequences) < 50 {
+		t.Errorf("got %d distinct sequences in 100 ids, want them spread over the sequence space", len(sequences))
+	}
+}
+
+func TestWithRelaxedSequenceClose(t *testing.T) {
+	sf := newRelaxed(t)
+	sf.MustNextID()
+	sf.Close()
+
+	if _, err := sf.NextID(); !errors.Is(err, snowflake.ErrClosed) {
+		t.Errorf("NextID after Close: err = %v, want ErrClosed", err)
+	}
+}
+
+func BenchmarkNextIDParallelRelaxed(b *testing.B) {
+	sf := newRelaxed(b)
+	defer sf.Close()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			sf.MustNextID()
+		}
+	})
+}
@@ -0,0 +1,19 @@
+//go:build go1.23
+
+package snowflake
+
+import "iter"
+
+// Seq 返回依次生成 n 个 ID 的迭代器，可以直接用于 for id := range sf.Seq(1000)
+// 每次迭代调用一次 NextID，ID 与连续调用 NextID 一样严格递增；循环提前 break 时不再生成剩下的 ID
+// 生成出错（时钟回拨、时间戳溢出等）时迭代提前结束，需要处理错误时使用 NextIDs 或 NextID
+func (s *SnowFlake) Seq(n int) iter.Seq[int64] {
+	return func(yield func(int64) bool) {
+		for i := 0; i < n; i++ {
+			id, err := s.NextID()
+			if err != nil || !yield(id) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+//go:build go1.23
+
+package snowflake_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestSeq(t *testing.T) {
+	sf := snowflake.NewWith(testStartTime, 1, 2)
+
+	var ids []int64
+	for id := range sf.Seq(5000) {
+		ids = append(ids, id)
+	}
+	if len(ids) != 5000 {
+		t.Fatalf("got %d ids, want 5000", len(ids))
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Fatalf("ids[%d] = %d not greater than ids[%d] = %d", i, ids[i], i-1, ids[i-1])
+		}
+	}
+
+	// break 之后不再生成
+	fixed := snowflake.NewWithClock(testStartTime, newFakeClock(testNow), 1, 2)
+	count := 0
+	for range fixed.Seq(100) {
+		if count++; count == 10 {
+			break
+		}
+	}
+	if seq := fixed.Decompose(fixed.MustNextID()).Sequence; seq != 10 {
+		t.Errorf("sequence after break = %d, want 10", seq)
+	}
+
+	// 出错时提前结束
+	last := testStartTime.Add(time.Duration(1<<41) * time.Millisecond)
+	overflow := snowflake.NewWithClock(testStartTime, newFakeClock(last), 1, 2)
+	for id := range overflow.Seq(3) {
+		t.Errorf("Seq yielded %d after timestamp overflow", id)
+	}
+}
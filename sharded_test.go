@@ -0,0 +1,71 @@
+package snowflake_test
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestNewSharded(t *testing.T) {
+	if _, err := snowflake.NewSharded(testStartTime, nil); err == nil {
+		t.Error("NewSharded with no nodeIDs should return an error")
+	}
+	if _, err := snowflake.NewSharded(testStartTime, []uint16{1, 1}); err == nil {
+		t.Error("NewSharded with duplicate nodeIDs should return an error")
+	}
+
+	s, err := snowflake.NewSharded(testStartTime, []uint16{1, 2, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if got := s.NodeIDs(); len(got) != 4 || got[0] != 1 || got[3] != 4 {
+		t.Errorf("NodeIDs() = %v, want [1 2 3 4]", got)
+	}
+
+	const goroutines, perGoroutine = 16, 3000
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		ids = make(map[int64]bool, goroutines*perGoroutine)
+	)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := make([]int64, 0, perGoroutine)
+			for i := 0; i < perGoroutine; i++ {
+				local = append(local, s.MustNextID())
+			}
+			mu.Lock()
+			for _, id := range local {
+				ids[id] = true
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if len(ids) != goroutines*perGoroutine {
+		t.Errorf("got %d unique ids, want %d", len(ids), goroutines*perGoroutine)
+	}
+}
+
+func BenchmarkNextIDParallelSharded(b *testing.B) {
+	nodeIDs := make([]uint16, runtime.GOMAXPROCS(0))
+	for i := range nodeIDs {
+		nodeIDs[i] = uint16(i)
+	}
+	s, err := snowflake.NewSharded(testStartTime, nodeIDs)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer s.Close()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.MustNextID()
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package snowflake
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// sharedStateSize 共享文件中状态的字节数：8 字节的上次时间戳和 8 字节的序号，均为大端序
+const sharedStateSize = 16
+
+// SharedSnowFlake 与同一主机上的其他进程共享节点 ID 的生成器，上次时间戳和序号保存在共享的文件中，
+// 每次生成时用文件锁（flock）互斥地读取、推进并写回，多个进程（如 prefork 的 worker）使用同一个节点 ID 也不会重复
+// 每个 ID 都需要加文件锁和两次文件读写的系统调用，比 SnowFlake 慢一个数量级以上（每个 ID 数微秒），
+// 所有进程争用同一把锁，每毫秒的容量也只是单个节点的 4096 个；只在无法给每个进程分配不同节点 ID 时使用
+type SharedSnowFlake struct {
+	// flock 锁的是打开的文件，同一进程内的 goroutine 之间还需要 mutex
+	mutex sync.Mutex
+	file  *os.File
+
+	// base 只用到其中不可变的配置：开始时间、机器 ID、位分配和时间源
+	base *SnowFlake
+}
+
+// NewWithSharedSequence 给定共享状态的文件路径、开始时间和 10 位的节点 ID（含义同 NewWithNode），创建 SharedSnowFlake
+// 使用同一节点 ID 的进程需要传入同一个 path，文件不存在时会创建；只支持 Linux 和 macOS，其他平台返回错误
+// 节点 ID 本来就是共享的，因此不会在进程内登记（见 NewWithStrict）
+func NewWithSharedSequence(path string, startTime time.Time, nodeID uint16) (*SharedSnowFlake, error) {
+	if err := defaultLayout.checkNodeID(nodeID); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	// 确认平台支持文件锁
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+	unlockFile(file)
+
+	dataCenterID, workerID := defaultLayout.splitNode(nodeID)
+	return &SharedSnowFlake{file: file, base: newWith(defaultLayout, startTime, dataCenterID, workerID)}, nil
+}
+
+// Close 关闭共享的文件，之后再生成 ID 返回 ErrClosed；可以多次调用
+func (s *SharedSnowFlake) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// NextID 获取一个 ID，时钟回拨时返回 *ClockBackwardsError，时间戳溢出时返回 ErrTimestampOverflow
+func (s *SharedSnowFlake) NextID() (int64, error) {
+	return s.NextIDContext(context.Background())
+}
+
+// MustNextID 同 NextID，但出错时 panic
+func (s *SharedSnowFlake) MustNextID() int64 {
+	id, err := s.NextID()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// TimeOf 同 SnowFlake.TimeOf
+func (s *SharedSnowFlake) TimeOf(id int64) time.Time {
+	return s.base.TimeOf(id)
+}
+
+// Decompose 同 SnowFlake.Decompose
+func (s *SharedSnowFlake) Decompose(id int64) Parts {
+	return s.base.Decompose(id)
+}
+
+// NextIDContext 同 NextID，等待下一毫秒时如果 ctx 被取消则返回 ctx.Err()
+func (s *SharedSnowFlake) NextIDContext(ctx context.Context) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.file == nil {
+		return 0, ErrClosed
+	}
+	if err := lockFile(s.file); err != nil {
+		return 0, err
+	}
+	defer unlockFile(s.file)
+
+	var state [sharedStateSize]byte
+	if _, err := s.file.ReadAt(state[:], 0); err != nil && err != io.EOF {
+		return 0, err
+	}
+	lastTimestamp := int64(binary.BigEndian.Uint64(state[:8]))
+	sequence := int64(binary.BigEndian.Uint64(state[8:]))
+
+	l := s.base.layout
+	timestamp := s.base.genTimestamp()
+	if timestamp < lastTimestamp {
+		return 0, &ClockBackwardsError{Delta: lastTimestamp - timestamp, unit: l.unit}
+	}
+
+	if timestamp == lastTimestamp {
+		sequence = (sequence + 1) & l.sequenceMask
+		// 当前毫秒内序号用完，持有文件锁等到下一毫秒
+		for sequence == 0 && timestamp <= lastTimestamp {
+			select {
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			default:
+			}
+			runtime.Gosched()
+			timestamp = s.base.genTimestamp()
+		}
+	} else {
+		sequence = 0
+	}
+
+	id, err := s.base.compose(timestamp, sequence)
+	if err != nil {
+		return 0, err
+	}
+
+	binary.BigEndian.PutUint64(state[:8], uint64(timestamp))
+	binary.BigEndian.PutUint64(state[8:], uint64(sequence))
+	if _, err := s.file.WriteAt(state[:], 0); err != nil {
+		return 0, fmt.Errorf("snowflake: failed to write shared sequence: %w", err)
+	}
+
+	return id, nil
+}
+
+// DataCenterID 返回生成器使用的 dataCenterID
+func (s *SharedSnowFlake) DataCenterID() uint8 {
+	return s.base.dataCenterID
+}
+
+// WorkerID 返回生成器使用的 workerID
+func (s *SharedSnowFlake) WorkerID() uint8 {
+	return s.base.workerID
+}
@@ -0,0 +1,19 @@
+//go:build !linux && !darwin
+
+package snowflake
+
+import (
+	"errors"
+	"os"
+)
+
+// errSharedUnsupported 当前平台不支持 NewWithSharedSequence 使用的文件锁
+var errSharedUnsupported = errors.New("snowflake: shared sequence is only supported on linux and darwin")
+
+func lockFile(f *os.File) error {
+	return errSharedUnsupported
+}
+
+func unlockFile(f *os.File) error {
+	return errSharedUnsupported
+}
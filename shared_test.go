@@ -0,0 +1,72 @@
+//go:build linux || darwin
+
+package snowflake_test
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestNewWithSharedSequence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snowflake.seq")
+
+	// 两个生成器分别打开同一个文件，模拟使用同一节点 ID 的两个进程
+	var gens []*snowflake.SharedSnowFlake
+	for i := 0; i < 2; i++ {
+		sf, err := snowflake.NewWithSharedSequence(path, testStartTime, 613)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sf.Close()
+		gens = append(gens, sf)
+	}
+	if dataCenterID, workerID := snowflake.SplitNodeID(613); gens[0].DataCenterID() != dataCenterID || gens[0].WorkerID() != workerID {
+		t.Errorf("node = %d, %d, want %d, %d", gens[0].DataCenterID(), gens[0].WorkerID(), dataCenterID, workerID)
+	}
+
+	const perGoroutine = 3000
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		ids = make(map[int64]bool)
+	)
+	for _, sf := range gens {
+		for g := 0; g < 2; g++ {
+			wg.Add(1)
+			go func(sf *snowflake.SharedSnowFlake) {
+				defer wg.Done()
+				local := make([]int64, perGoroutine)
+				for i := range local {
+					local[i] = sf.MustNextID()
+				}
+				if !snowflake.IsMonotonic(local) {
+					t.Error("ids from one goroutine are not strictly increasing")
+				}
+				mu.Lock()
+				for _, id := range local {
+					ids[id] = true
+				}
+				mu.Unlock()
+			}(sf)
+		}
+	}
+	wg.Wait()
+	if len(ids) != 4*perGoroutine {
+		t.Errorf("got %d unique ids, want %d", len(ids), 4*perGoroutine)
+	}
+
+	gens[0].Close()
+	if _, err := gens[0].NextID(); err != snowflake.ErrClosed {
+		t.Errorf("NextID() after Close = %v, want ErrClosed", err)
+	}
+
+	if _, err := snowflake.NewWithSharedSequence(path, testStartTime, 1024); err == nil {
+		t.Error("nodeID 1024 should return an error")
+	}
+	if _, err := snowflake.NewWithSharedSequence(filepath.Join(path, "missing"), testStartTime, 1); err == nil {
+		t.Error("an unopenable path should return an error")
+	}
+}
@@ -0,0 +1,23 @@
+//go:build linux || darwin
+
+package snowflake
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile 阻塞地获取 f 上的排他文件锁
+func lockFile(f *os.File) error {
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+// unlockFile 释放 f 上的文件锁
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -6,42 +6,159 @@ SnowFlake 的结构如下（每部分用-分开）:
 - 41 位时间戳（毫秒级），注意，41 位时间戳不是存储当前时间的时间戳，而是存储时间戳的差值（当前时间戳 - 开始时间戳），这样能存的时间更长，开始时间一般指定为项目启动时间，由程序指定。可以使用 69 年：`(1<<41)/(1000*60*60*24*365)`；
 - 10 位的机器相关位，可以部署在 1024 个节点，包括 5 位的 datacenterId（数据中心 ID） 和 5 位 workerId（工作机器 ID）；
 - 12 位系列号，毫秒内的计数。12 位的计数顺序号支持每个节点每毫秒（同一机器，同一时间戳）产生 4096 个 ID 序号；
+以上是默认的位分配（DefaultConfig），可以通过 NewWithConfig 自定义各部分的位数。
 */
 package snowflake
 
 import (
+	"context"
 	"fmt"
-	"net"
+	"math/rand"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-const (
-	sequenceMask   = 1<<12 - 1
-	workerMask     = 1<<5 - 1
-	dataCenterMask = 1<<5 - 1
-
-	workerLeftShift     = 12
-	dataCenterLeftShift = 17
-	timestampLeftShift  = 22
-)
+// DefaultEpoch New 使用的开始时间：2020-01-01 00:00:00 UTC
+// 固定的开始时间保证重启前后生成的 ID 仍然递增，不会因开始时间变化而重复
+var DefaultEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
 
 type SnowFlake struct {
+	// 放在第一个字段，保证 32 位平台上 64 位原子操作的对齐
+	counters counters
+	// WithRelaxedSequence 的随机数状态，同样使用原子操作，紧跟 counters 保证对齐
+	relaxedState uint64
+	// 是否已经 Close，非 0 表示已关闭；在锁内写入，使用原子操作读写，供不加锁的 relaxedID 检查
+	closed uint32
+
 	mutex sync.Mutex
 
-	sequence     int16
+	// 毫秒内的序号，位数由位分配决定，使用 int64 存储以支持超过 15 位的序号
+	sequence     int64
 	dataCenterID uint8
 	workerID     uint8
 
-	// 上次生成 ID 的时间戳（毫秒）
+	// 上次生成 ID 的时间戳，单位为位分配的时间单位（默认毫秒）
 	lastTimestamp int64
 
 	startTime time.Time
+
+	layout layout
+
+	clock Clock
+
+	// 可容忍的时钟回拨，回拨在此范围内时沿用上次的时间戳
+	maxBackwardTolerance time.Duration
+
+	waitStrategy WaitStrategy
+
+	// 时钟回拨超出 maxBackwardTolerance 时的处理方式
+	backwardsPolicy ClockBackwardsPolicy
+
+	// 不为 nil 时，BackwardsPanic 调用它而不是 panic，见 WithPanicHandler
+	panicHandler func(err error)
+
+	// 序号用完时在逻辑上推进时间戳，最多领先时钟的时间单位数，为 0 时总是等待，见 WithLogicalAdvance
+	maxLogicalDrift int64
+
+	// 不为 nil 时，序号用完后调用它得到下一个时间戳，取代内置的等待，见 WithWaitFunc
+	waitFunc func(lastTs int64) int64
+
+	// 当前毫秒内序号用完时的回调，以及在锁内记录、等待在锁外回调的时间戳
+	onSequenceExhausted func(ts int64)
+	exhausted           []int64
+
+	// lastTimestamp 是否来自 RestoreState 且还未被新的时间戳取代
+	restored bool
+
+	// release 释放通过 Registrar 获取的节点 ID
+	release func()
+	// 是否在进程内登记了节点，见 register
+	registered bool
+
+	// GenerateAt 使用的每毫秒已用序号数，与 NextID 的 sequence 相互独立，最多 maxBackfillEntries 项
+	backfill map[int64]int64
+	// GenerateAt 用过的最晚的时间戳
+	backfillLatest int64
+	// 早于该时间戳的时间不再记录已用序号，GenerateAt 拒绝这些时间，见 GenerateAt
+	backfillFloor int64
+
+	// 不为 nil 时，每个新毫秒的起始序号取随机值，见 WithRandomSequenceStart
+	sequenceRand *rand.Rand
+	// 每个新毫秒的起始序号，见 WithSequenceOffset
+	sequenceOffset int64
+	// 为 true 时 NextID 不加锁，序号取随机值，见 WithRelaxedSequence
+	relaxed bool
+
+	// 不为 nil 时记录最近一秒生成的 ID 数，见 WithRateTracking
+	rate *rateTracker
+
+	// dataCenterID 和 workerID 是否由调用方显式指定，而不是自动获取的，见 Validate
+	explicitNode bool
+
+	// 运行中的 Stream：key 是 goroutine 退出时关闭的 channel，value 用于停止该 goroutine
+	streams map[chan struct{}]context.CancelFunc
+}
+
+// counters 生成 ID 过程中的计数，使用原子操作读写，读取时不需要加锁
+type counters struct {
+	// 成功生成的 ID 数
+	generated uint64
+	// 当前毫秒内序号用完、需要等待下一毫秒的次数
+	sequenceExhausted uint64
+	// 检测到时钟回拨的次数，包括容忍范围内的回拨
+	clockBackwards uint64
 }
 
 // NewWith 给定开始时间和可选的 dataCenterID 和 workerID（注意两者的顺序）
-// 如果 ids 没传，则使用 machineID
+// 如果 ids 没传，则使用 machineID：依次尝试 IP 地址、主机名加进程 ID 的哈希
+// 超出 5 位的 dataCenterID 和 workerID 会被截掉高位（如 40 变成 8），可能导致不同节点冲突，
+// 需要校验时使用 NewWithStrict
+// 所有构造函数都会将 startTime 截断到时间戳的单位（默认毫秒），ID 中的时间戳按截断后的开始时间计算，
+// TimeOf、Decompose 等解码出的时间与生成时完全一致，StartTime 返回的也是截断后的值
 func NewWith(startTime time.Time, ids ...uint8) *SnowFlake {
+	return newWith(defaultLayout, startTime, ids...)
+}
+
+// NewWithStrict 同 NewWith，但 dataCenterID 或 workerID 超出 5 位（大于 31）时返回错误，而不是截掉高位；
+// startTime 晚于当前时间时返回 ErrFutureStartTime
+// 同一进程内已有通过 NewWithStrict、NewWithNode 或 NewWithOptions 创建且未 Close 的生成器使用相同的节点时也返回错误
+func NewWithStrict(startTime time.Time, dataCenterID, workerID uint8) (*SnowFlake, error) {
+	if err := defaultLayout.checkNode(dataCenterID, workerID); err != nil {
+		return nil, err
+	}
+	if now := time.Now(); startTime.After(now) {
+		return nil, futureStartTimeError(startTime, now)
+	}
+	s := newWith(defaultLayout, startTime, dataCenterID, workerID)
+	if err := s.register(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewWithClock 同 NewWith，但使用 clock 作为时间源，clock 为 nil 时使用系统时间
+func NewWithClock(startTime time.Time, clock Clock, ids ...uint8) *SnowFlake {
+	s := newWith(defaultLayout, startTime, ids...)
+	if clock != nil {
+		s.clock = clock
+	}
+	return s
+}
+
+// NewWithEpochMs 同 NewWith，开始时间由 Unix 毫秒时间戳 epochMs 给出，便于与其他语言的实现约定同一个整数形式的开始时间
+// epochMs 晚于当前时间时返回 ErrFutureStartTime
+func NewWithEpochMs(epochMs int64, ids ...uint8) (*SnowFlake, error) {
+	startTime := time.UnixMilli(epochMs).UTC()
+	if now := time.Now(); startTime.After(now) {
+		return nil, futureStartTimeError(startTime, now)
+	}
+	return newWith(defaultLayout, startTime, ids...), nil
+}
+
+func newWith(l layout, startTime time.Time, ids ...uint8) *SnowFlake {
 	var dataCenterID, workerID uint8
 
 	idLen := len(ids)
@@ -51,86 +168,523 @@ func NewWith(startTime time.Time, ids ...uint8) *SnowFlake {
 		dataCenterID = ids[0]
 		workerID = ids[0]
 	} else {
-		dataCenterID, workerID = machineID()
+		dataCenterID, workerID, _ = machineID()
 	}
 
 	return &SnowFlake{
-		startTime:    startTime.UTC(),
-		dataCenterID: dataCenterID & dataCenterMask,
-		workerID:     workerID & workerMask,
+		startTime:    startTime.UTC().Truncate(l.unit),
+		dataCenterID: dataCenterID & uint8(l.dataCenterMask),
+		workerID:     workerID & uint8(l.workerMask),
+		layout:       l,
+		clock:        systemClock{},
+		explicitNode: idLen > 0,
 	}
 }
 
-func New() *SnowFlake {
-	now := time.Now()
-	startTime := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
-	dataCenterID, workerID := machineID()
-	return NewWith(startTime, dataCenterID, workerID)
+// New 根据 opts 创建 SnowFlake，不传 opts 时使用 DefaultEpoch 作为开始时间，使用 machineID 作为 dataCenterID 和 workerID
+// 与 NewWith 一样，获取机器 ID 失败时使用 0, 0，超出范围的 ID 会被截掉高位；
+// 只有 opts 本身不合法（如 WithConfig 传入非法的位分配）时才会 panic，需要返回错误时使用 NewWithOptions
+func New(opts ...Option) *SnowFlake {
+	s, err := newWithOptions(false, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return s
 }
 
-// NextID 获取一个 ID
-func (s *SnowFlake) NextID() int64 {
-	now := time.Now().UTC()
-	millisecond := now.UnixNano() / 1e6
-	if millisecond < s.lastTimestamp {
-		panic("Clock moved backwards, Refusing to generate id")
+// Clone 返回一个配置相同的新生成器：开始时间、dataCenterID、workerID、位分配、时钟和各项设置都相同，
+// 但上次的时间戳、序号和计数都从零开始，也不持有通过 Registrar 获取的节点 ID
+// 克隆出的生成器与原生成器节点 ID 相同，同时使用时会生成重复的 ID
+func (s *SnowFlake) Clone() *SnowFlake {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	c := &SnowFlake{
+		dataCenterID:         s.dataCenterID,
+		workerID:             s.workerID,
+		startTime:            s.startTime,
+		layout:               s.layout,
+		clock:                s.clock,
+		maxBackwardTolerance: s.maxBackwardTolerance,
+		waitStrategy:         s.waitStrategy,
+		backwardsPolicy:      s.backwardsPolicy,
+		panicHandler:         s.panicHandler,
+		maxLogicalDrift:      s.maxLogicalDrift,
+		waitFunc:             s.waitFunc,
+		sequenceOffset:       s.sequenceOffset,
+		relaxed:              s.relaxed,
+		explicitNode:         s.explicitNode,
+		relaxedState:         atomic.LoadUint64(&s.relaxedState) ^ 0x5DEECE66D,
+		onSequenceExhausted:  s.onSequenceExhausted,
+	}
+	if s.rate != nil {
+		c.rate = &rateTracker{}
+	}
+	// rand.Rand 不是并发安全的，克隆出的生成器使用自己的随机源
+	if s.sequenceRand != nil {
+		c.sequenceRand = rand.New(rand.NewSource(s.sequenceRand.Int63()))
 	}
+	return c
+}
+
+// isClosed 返回是否已经 Close
+func (s *SnowFlake) isClosed() bool {
+	return atomic.LoadUint32(&s.closed) != 0
+}
 
+// Close 停止所有 Stream 的 goroutine，归还通过 Registrar 获取的节点 ID 并释放进程内登记的节点，可以多次调用
+// Close 之后生成 ID 的方法都返回 ErrClosed
+func (s *SnowFlake) Close() error {
 	s.mutex.Lock()
+	atomic.StoreUint32(&s.closed, 1)
+	s.unregister()
+	streams := s.streams
+	s.streams = nil
+	release := s.release
+	s.release = nil
+	s.mutex.Unlock()
 
-	// 同一毫秒，进行毫秒内序号递增
-	if millisecond == s.lastTimestamp {
-		s.sequence = (s.sequence + 1) & sequenceMask
+	for done, cancel := range streams {
+		cancel()
+		<-done
+	}
+	if release != nil {
+		release()
+	}
+	return nil
+}
+
+// SetMaxBackwardTolerance 设置可容忍的时钟回拨，默认为 0，即不容忍任何回拨
+// 回拨不超过 d 时，生成 ID 会沿用上次的时间戳继续递增序号，而不是报错；应在生成 ID 之前设置
+func (s *SnowFlake) SetMaxBackwardTolerance(d time.Duration) {
+	s.mutex.Lock()
+	s.maxBackwardTolerance = d
+	s.mutex.Unlock()
+}
+
+// NextID 获取一个 ID，时钟回拨时返回 *ClockBackwardsError（errors.Is 判断为 ErrClockBackwards），时间戳溢出时返回 ErrTimestampOverflow
+func (s *SnowFlake) NextID() (int64, error) {
+	return s.NextIDContext(context.Background())
+}
+
+// MustNextID 同 NextID，但出错时 panic，只应在确定不会出错或出错即应终止的场景使用
+func (s *SnowFlake) MustNextID() int64 {
+	id, err := s.NextID()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// NextIDSafe 同 NextID，返回的错误同样可以用 errors.Is 与 ErrClockBackwards、ErrTimestampOverflow 等比较
+//
+// Deprecated: NextID 已经返回 error，直接使用 NextID
+func (s *SnowFlake) NextIDSafe() (int64, error) {
+	return s.NextID()
+}
+
+// NextIDContext 获取一个 ID，当前毫秒内序号用完需要等待下一毫秒时，
+// 如果 ctx 被取消则返回 ctx.Err()
+func (s *SnowFlake) NextIDContext(ctx context.Context) (int64, error) {
+	if s.layout.config.Unsigned {
+		return 0, ErrUnsignedLayout
+	}
+	if s.relaxed {
+		return s.relaxedID()
+	}
+
+	s.mutex.Lock()
+	defer s.unlock()
+
+	return s.generate(ctx)
+}
+
+// nonBlocking TryNextID 使用的已取消的 ctx，需要等待时钟时立即返回
+var nonBlocking = func() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}()
+
+// TryNextID 同 NextID，但从不等待：当前毫秒内序号用完时返回包装了 ErrSequenceExhausted 的错误，
+// 需要等待时钟追上的回拨（BackwardsWait 或不超过一秒的回拨）返回 *ClockBackwardsError，调用方可以稍后重试
+func (s *SnowFlake) TryNextID() (int64, error) {
+	return s.NextIDContext(nonBlocking)
+}
+
+// NextIDs 批量获取 n 个 ID，整个过程只加锁一次
+// 返回的 ID 与连续调用 n 次 NextID 一样严格递增；每个 ID 都会检查时间戳溢出等错误，
+// 出错时返回已经生成的部分，以及说明生成了多少个的错误，可以用 errors.Is 判断原始错误（如 ErrTimestampOverflow）
+func (s *SnowFlake) NextIDs(n int) ([]int64, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	ids := make([]int64, n)
+	filled, err := s.fill(ids)
+	if err != nil && err != ErrUnsignedLayout {
+		err = fmt.Errorf("snowflake: generated %d of %d ids: %w", filled, n, err)
+	}
+	return ids[:filled], err
+}
+
+// FillIDs 生成 len(dst) 个 ID 填入 dst，返回写入的个数，整个过程只加锁一次且不分配内存
+// 写入的 ID 与 NextIDs 一样严格递增；出错时停止填充，返回值小于 len(dst)，需要错误原因时使用 NextIDs
+func (s *SnowFlake) FillIDs(dst []int64) int {
+	n, _ := s.fill(dst)
+	return n
+}
+
+// fill 在一次加锁内填充 dst，返回写入的个数和出错时的错误
+func (s *SnowFlake) fill(dst []int64) (int, error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	if s.layout.config.Unsigned {
+		return 0, ErrUnsignedLayout
+	}
+
+	s.mutex.Lock()
+	defer s.unlock()
+
+	for i := range dst {
+		id, err := s.generate(context.Background())
+		if err != nil {
+			return i, err
+		}
+		dst[i] = id
+	}
+
+	return len(dst), nil
+}
+
+// NextIDWithTime 获取一个 ID，同时返回 ID 中记录的生成时间（同 TimeOf），出错情况同 NextID
+// 返回的时间与 ID 中的时间戳完全一致，可以直接作为记录的创建时间，而不必再调用 time.Now 或 TimeOf
+func (s *SnowFlake) NextIDWithTime() (int64, time.Time, error) {
+	id, err := s.NextID()
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return id, s.TimeOf(id), nil
+}
+
+// Peek 返回下一个 ID 的预测值，不推进序号和上次时间戳
+// 只是按当前时钟的估计：之后时钟前进、有其他调用先生成了 ID 或使用 WithRandomSequenceStart 时，
+// 下一次 NextID 的结果不一定等于 Peek 的返回值；时间戳溢出或生成器已关闭时返回 0
+func (s *SnowFlake) Peek() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isClosed() {
+		return 0
+	}
+
+	timestamp, sequence := s.genTimestamp(), s.sequenceOffset
+	if timestamp <= s.lastTimestamp {
+		timestamp = s.lastTimestamp
+		sequence = (s.sequence + 1) & s.layout.sequenceMask
+		// 序号用完时下一个 ID 在下一个时间单位
+		if sequence == 0 {
+			timestamp, sequence = timestamp+1, s.sequenceOffset
+		}
+	}
+
+	id, err := s.compose(timestamp, sequence)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// NextUID 获取一个 uint64 的 ID，出错情况同 NextID
+// 使用 uint64 位分配（如 DefaultUnsignedConfig）时可以用上符号位，时间戳的可用时间翻倍；
+// 这样的 ID 可能超出 int64 的范围，不能与 int64 的 ID 混用。使用 int64 位分配时结果与 NextID 相同
+func (s *SnowFlake) NextUID() (uint64, error) {
+	s.mutex.Lock()
+	defer s.unlock()
+
+	id, err := s.generate(context.Background())
+	return uint64(id), err
+}
+
+// MustNextUID 同 NextUID，但出错时 panic
+func (s *SnowFlake) MustNextUID() uint64 {
+	id, err := s.NextUID()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// unlock 释放锁，并在锁外调用 OnSequenceExhausted 回调，避免回调中再调用生成器时死锁
+func (s *SnowFlake) unlock() {
+	exhausted := s.exhausted
+	s.exhausted = nil
+	callback := s.onSequenceExhausted
+	s.mutex.Unlock()
+
+	for _, ts := range exhausted {
+		callback(ts)
+	}
+}
+
+// generate 读取时钟并生成一个 ID，调用方需持有锁
+// 对 lastTimestamp 和 sequence 的读写都在锁内，避免数据竞争
+func (s *SnowFlake) generate(ctx context.Context) (int64, error) {
+	if s.isClosed() {
+		return 0, ErrClosed
+	}
+
+	// 实际使用的时间戳为 max(当前时间, lastTimestamp)，保证 ID 严格递增
+	timestamp := s.genTimestamp()
+	if timestamp < s.lastTimestamp && s.lastTimestamp-timestamp <= s.maxLogicalDrift {
+		// lastTimestamp 是 WithLogicalAdvance 推进的，领先时钟在允许范围内，不算回拨
+		timestamp = s.lastTimestamp
+	} else if timestamp < s.lastTimestamp {
+		atomic.AddUint64(&s.counters.clockBackwards, 1)
+
+		// lastTimestamp 来自 RestoreState 时，无论回拨多少都沿用
+		delta := s.lastTimestamp - timestamp
+		if d := time.Duration(delta) * s.layout.unit; !s.restored && d > s.maxBackwardTolerance {
+			err := &ClockBackwardsError{Delta: delta, unit: s.layout.unit}
+			policy := s.backwardsPolicy
+			if d <= leapSecondTolerance && policy != BackwardsAdvanceLogical {
+				// 闰秒前后的回拨，按 BackwardsWait 处理，见 leapSecondTolerance
+				policy = BackwardsWait
+			}
+			switch policy {
+			case BackwardsPanic:
+				if s.panicHandler == nil {
+					panic(err)
+				}
+				s.panicHandler(err)
+				return 0, err
+			case BackwardsWait:
+				if _, waitErr := s.waitTimestamp(ctx, s.lastTimestamp); waitErr != nil {
+					if ctx == nonBlocking {
+						return 0, err
+					}
+					return 0, waitErr
+				}
+			case BackwardsAdvanceLogical:
+			default:
+				return 0, err
+			}
+		}
+		// 回拨在容忍范围内（或按 ClockBackwardsPolicy 继续生成），沿用上次的时间戳继续递增序号，
+		// 序号用完时等待时钟走过该时间戳
+		timestamp = s.lastTimestamp
+	}
+
+	timestamp, sequence, err := s.advance(ctx, timestamp)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := s.compose(timestamp, sequence)
+	if err != nil {
+		return 0, err
+	}
+	atomic.AddUint64(&s.counters.generated, 1)
+	if s.rate != nil {
+		s.rate.record(timestamp * int64(s.layout.unit))
+	}
+
+	return id, nil
+}
+
+// advance 推进序号和上次时间戳，返回本次 ID 使用的时间戳和序号，调用方需持有锁
+func (s *SnowFlake) advance(ctx context.Context, timestamp int64) (int64, int64, error) {
+	// 同一时间戳（默认为毫秒，见 Config.Unit），进行毫秒内序号递增
+	if timestamp == s.lastTimestamp {
+		s.sequence = (s.sequence + 1) & s.layout.sequenceMask
 		// 当前毫秒内序号用完，堵塞到下一毫秒
 		if s.sequence == 0 {
-			for millisecond <= s.lastTimestamp {
-				millisecond = genMillisecond()
+			atomic.AddUint64(&s.counters.sequenceExhausted, 1)
+			if s.onSequenceExhausted != nil {
+				s.exhausted = append(s.exhausted, s.lastTimestamp)
+			}
+
+			var err error
+			switch now := s.genTimestamp(); {
+			case s.waitFunc != nil:
+				// 自定义的等待函数返回的时间戳没有前进时，仍使用内置的等待，保证不会重复
+				if timestamp = s.waitFunc(s.lastTimestamp); timestamp <= s.lastTimestamp {
+					timestamp, err = s.waitNextTimestamp(ctx)
+				}
+			case now > s.lastTimestamp:
+				timestamp = now
+			case s.backwardsPolicy == BackwardsAdvanceLogical && now < s.lastTimestamp,
+				s.lastTimestamp+1-now <= s.maxLogicalDrift:
+				// 时钟回拨后仍落后于 lastTimestamp，或推进后领先时钟不超过 maxLogicalDrift，
+				// 不等待，直接在逻辑上推进一个时间单位
+				timestamp = s.lastTimestamp + 1
+			default:
+				timestamp, err = s.waitNextTimestamp(ctx)
+			}
+			if err != nil {
+				// 保持序号用完的状态，避免下次调用在同一毫秒内重复使用序号
+				s.sequence = s.layout.sequenceMask
+				return 0, 0, err
 			}
+			s.sequence = s.firstSequence()
 		}
 	} else {
 		// 时间戳改变，毫秒内序号重置
-		s.sequence = 0
+		s.sequence = s.firstSequence()
+	}
+	if timestamp != s.lastTimestamp {
+		s.restored = false
 	}
-	s.lastTimestamp = millisecond
-	sequence := s.sequence
+	s.lastTimestamp = timestamp
 
-	s.mutex.Unlock()
+	return timestamp, s.sequence, nil
+}
 
-	elaspedMillisecond := millisecond - s.startTime.UnixNano()/1e6
+// firstSequence 返回新毫秒的起始序号，默认为 0，设置了 WithSequenceOffset 时为 offset
+// 随机起始时只取 offset 之后剩余序号空间的前一半，序号递增到最大值后仍按用完处理，保证毫秒内严格递增，且每毫秒至少有一半的容量
+func (s *SnowFlake) firstSequence() int64 {
+	if s.sequenceRand == nil {
+		return s.sequenceOffset
+	}
+	return s.sequenceOffset + s.sequenceRand.Int63n((s.layout.sequenceMask+1-s.sequenceOffset+1)/2)
+}
 
-	return elaspedMillisecond<<timestampLeftShift |
-		int64(s.dataCenterID)<<dataCenterLeftShift |
-		int64(s.workerID)<<workerLeftShift |
-		int64(sequence)
+// waitNextTimestamp 堵塞到 lastTimestamp 的下一个时间单位（默认为下一毫秒），ctx 被取消时返回 ctx.Err()
+func (s *SnowFlake) waitNextTimestamp(ctx context.Context) (int64, error) {
+	timestamp, err := s.waitTimestamp(ctx, s.lastTimestamp+1)
+	if err != nil && ctx == nonBlocking {
+		return 0, fmt.Errorf("%w: timestamp %d", ErrSequenceExhausted, s.lastTimestamp)
+	}
+	return timestamp, err
 }
 
-func (s *SnowFlake) String() string {
-	return fmt.Sprintf("start_time:%s, data_center:%d, worker_id:%d, sequence:%d",
-		s.startTime, s.dataCenterID, s.workerID, s.sequence)
+// waitTimestamp 堵塞到时钟不早于 target，返回当时的时间戳，ctx 被取消时返回 ctx.Err()
+func (s *SnowFlake) waitTimestamp(ctx context.Context, target int64) (int64, error) {
+	timestamp := s.genTimestamp()
+	for timestamp < target {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		default:
+		}
+		s.pause()
+		timestamp = s.genTimestamp()
+	}
+
+	return timestamp, nil
 }
 
-func machineID() (uint8, uint8) {
-	as, err := net.InterfaceAddrs()
-	if err != nil {
-		return 0, 0
+// compose 按位拼装 ID，时间戳差值超出位分配的范围时返回 ErrTimestampOverflow，
+// 为负数（开始时间晚于当前时间）时返回 ErrFutureStartTime
+func (s *SnowFlake) compose(timestamp int64, sequence int64) (int64, error) {
+	elapsed := timestamp - s.startTime.UnixNano()/int64(s.layout.unit)
+	if elapsed < 0 {
+		return 0, ErrFutureStartTime
+	}
+	if elapsed > s.layout.maxElapsed {
+		return 0, fmt.Errorf("%w: elapsed %d exceeds %d", ErrTimestampOverflow, elapsed, s.layout.maxElapsed)
 	}
 
-	for _, a := range as {
-		ipnet, ok := a.(*net.IPNet)
-		if !ok || ipnet.IP.IsLoopback() {
-			continue
-		}
+	return elapsed<<s.layout.timestampLeftShift |
+		int64(s.dataCenterID)<<s.layout.dataCenterLeftShift |
+		int64(s.workerID)<<s.layout.workerLeftShift |
+		sequence<<s.layout.sequenceLeftShift, nil
+}
 
-		ip := ipnet.IP.To4()
-		if ip != nil {
-			return ip[2], ip[3]
-		}
+// RemainingLifetime 返回距离时间戳溢出还有多长时间，已经溢出时返回 0
+// 默认位分配下，从开始时间起大约可以使用 69 年，使用微秒时间戳时只有约 25 天；
+// 超出 time.Duration 的范围（约 292 年，如毫秒时间戳超过 43 位）时返回 time.Duration 的最大值
+func (s *SnowFlake) RemainingLifetime() time.Duration {
+	lifetime, ok := s.layout.duration(s.layout.maxElapsed)
+	if !ok {
+		return lifetime
+	}
+	end := s.startTime.Add(lifetime)
+	if remaining := end.Sub(s.clock.Now()); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// String 返回生成器的开始时间、节点和当前序号，如 start_time:2020-01-01 00:00:00 +0000 UTC, data_center:1, worker_id:2, sequence:0
+// 不经过 fmt，每次调用只分配一次内存，适合逐个 ID 打日志
+func (s *SnowFlake) String() string {
+	s.mutex.Lock()
+	startTime, dataCenterID, workerID, sequence := s.startTime, s.dataCenterID, s.workerID, s.sequence
+	s.mutex.Unlock()
+
+	var buf [64]byte
+	var sb strings.Builder
+	sb.Grow(96)
+	sb.WriteString("start_time:")
+	// 与 time.Time.String 的格式相同
+	sb.Write(startTime.AppendFormat(buf[:0], "2006-01-02 15:04:05.999999999 -0700 MST"))
+	sb.WriteString(", data_center:")
+	sb.Write(strconv.AppendUint(buf[:0], uint64(dataCenterID), 10))
+	sb.WriteString(", worker_id:")
+	sb.Write(strconv.AppendUint(buf[:0], uint64(workerID), 10))
+	sb.WriteString(", sequence:")
+	sb.Write(strconv.AppendInt(buf[:0], sequence, 10))
+	return sb.String()
+}
+
+// RemainingInMillis 返回当前毫秒内还能生成多少个 ID 而不需要等待下一毫秒
+// 时钟已经进入新的毫秒时，返回一整个毫秒的容量（默认 4096），使用 WithRandomSequenceStart 时这只是上限；
+// 时钟落后于上次的时间戳（时钟回拨、逻辑时钟超前或恢复了更晚的状态）时，下一个 ID 仍使用上次的时间戳或者报错，
+// 因此返回上次时间戳剩余的序号数；使用微秒时间戳（Config.Unit）时，毫秒均指微秒
+func (s *SnowFlake) RemainingInMillis() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.genTimestamp() > s.lastTimestamp {
+		return s.perUnit()
 	}
+	return int(s.layout.sequenceMask) - int(s.sequence)
+}
+
+// MaxIDsPerMillisecond 返回每毫秒最多能生成的 ID 数，由序号的位数和时间单位决定（默认 12 位、毫秒，即 4096）
+func (s *SnowFlake) MaxIDsPerMillisecond() int {
+	return s.perUnit() * int(time.Millisecond/s.layout.unit)
+}
+
+// MaxIDsPerSecond 返回每秒最多能生成的 ID 数
+// 使用微秒时间戳时可能超过 32 位 int 的范围，因此返回 int64
+func (s *SnowFlake) MaxIDsPerSecond() int64 {
+	return int64(s.perUnit()) * int64(time.Second/s.layout.unit)
+}
+
+// perUnit 每个时间单位内可用的序号数，设置了 WithSequenceOffset 时需要减去 offset
+func (s *SnowFlake) perUnit() int {
+	return int(s.layout.sequenceMask + 1 - s.sequenceOffset)
+}
+
+// DataCenterID 返回数据中心 ID
+func (s *SnowFlake) DataCenterID() uint8 {
+	return s.dataCenterID
+}
+
+// WorkerID 返回工作机器 ID
+func (s *SnowFlake) WorkerID() uint8 {
+	return s.workerID
+}
+
+// StartTime 返回开始时间（UTC），已截断到时间戳的单位（默认毫秒）
+func (s *SnowFlake) StartTime() time.Time {
+	return s.startTime
+}
+
+// Epoch 同 StartTime，返回计算时间戳差值的开始时间（UTC）
+func (s *SnowFlake) Epoch() time.Time {
+	return s.startTime
+}
 
-	return 0, 0
+// Layout 返回生成器使用的位分配，在外部拆解该生成器生成的 ID 时使用
+func (s *SnowFlake) Layout() Config {
+	return s.layout.config
 }
 
-// genMillisecond 获取当前 UTC 时间的时间戳（毫秒表示）
-func genMillisecond() int64 {
-	return time.Now().UTC().UnixNano() / 1e6
+// genTimestamp 获取时间源当前时间的时间戳，单位为位分配的时间单位（默认毫秒）
+func (s *SnowFlake) genTimestamp() int64 {
+	return s.clock.Now().UnixNano() / int64(s.layout.unit)
 }
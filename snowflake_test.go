@@ -1,7 +1,12 @@
 package snowflake_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -12,9 +17,591 @@ func TestNextID(t *testing.T) {
 	sf := snowflake.New()
 	for i := 0; i < 100; i++ {
 		go func(i int) {
-			fmt.Println(i, sf.NextID(), sf.String())
+			fmt.Println(i, sf.MustNextID(), sf.String())
 		}(i)
 	}
 
 	time.Sleep(2e9)
 }
+
+func TestNextIDSafe(t *testing.T) {
+	sf := snowflake.New()
+	prev, err := sf.NextIDSafe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10000; i++ {
+		id, err := sf.NextIDSafe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= prev {
+			t.Fatalf("id %d is not greater than previous %d", id, prev)
+		}
+		prev = id
+	}
+}
+
+func TestAccessors(t *testing.T) {
+	startTime := time.Date(2020, 1, 1, 8, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	sf := snowflake.NewWith(startTime, 3, 40)
+
+	if got := sf.DataCenterID(); got != 3 {
+		t.Errorf("DataCenterID = %d, want 3", got)
+	}
+	// 超出 5 位的部分会被截掉
+	if got := sf.WorkerID(); got != 40&31 {
+		t.Errorf("WorkerID = %d, want %d", got, 40&31)
+	}
+	if got := sf.StartTime(); !got.Equal(startTime) || got.Location() != time.UTC {
+		t.Errorf("StartTime = %s, want %s in UTC", got, startTime)
+	}
+}
+
+func TestClone(t *testing.T) {
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(testNow), 3, 7)
+	sf.SetMaxBackwardTolerance(time.Second)
+	first := sf.MustNextID()
+	sf.MustNextID()
+
+	c := sf.Clone()
+	if c.DataCenterID() != 3 || c.WorkerID() != 7 || !c.StartTime().Equal(testStartTime) {
+		t.Errorf("Clone() = %s, want the same node and start time as %s", c, sf)
+	}
+	// 序号从零开始，同一毫秒内生成与原生成器第一个相同的 ID
+	if got := c.MustNextID(); got != first {
+		t.Errorf("Clone().MustNextID() = %d, want %d", got, first)
+	}
+	if got := sf.MustNextID(); got != first+2 {
+		t.Errorf("original MustNextID() after Clone = %d, want %d", got, first+2)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	times := append(repeatTime(testNow, 4096*2+1), testNow.Add(time.Millisecond))
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(times...), 1, 2)
+
+	// Peek 不推进状态，多次调用结果相同且等于下一个 ID
+	for i := 0; i < 3; i++ {
+		peeked := sf.Peek()
+		if again := sf.Peek(); again != peeked {
+			t.Fatalf("Peek() = %d then %d, want stable", peeked, again)
+		}
+		if id := sf.MustNextID(); id != peeked {
+			t.Errorf("MustNextID() = %d, want peeked %d", id, peeked)
+		}
+	}
+
+	// 序号用完时预测下一毫秒的第一个 ID
+	for i := 3; i < 4096; i++ {
+		sf.MustNextID()
+	}
+	if got, want := sf.Peek(), sf.MustNextID(); got != want {
+		t.Errorf("Peek() at exhausted sequence = %d, want %d", got, want)
+	}
+
+	sf.Close()
+	if got := sf.Peek(); got != 0 {
+		t.Errorf("Peek() after Close = %d, want 0", got)
+	}
+}
+
+func TestString(t *testing.T) {
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(testNow), 1, 2)
+	sf.MustNextID()
+	sf.MustNextID()
+
+	want := "start_time:2020-01-01 00:00:00 +0000 UTC, data_center:1, worker_id:2, sequence:1"
+	if got := sf.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func BenchmarkNextID(b *testing.B) {
+	sf := snowflake.NewWith(testStartTime, 1, 2)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sf.MustNextID()
+	}
+}
+
+func BenchmarkNextIDs(b *testing.B) {
+	sf := snowflake.NewWith(testStartTime, 1, 2)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sf.NextIDs(100)
+	}
+}
+
+func BenchmarkString(b *testing.B) {
+	sf := snowflake.NewWith(testStartTime, 1, 2)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = sf.String()
+	}
+}
+
+func TestNextIDs(t *testing.T) {
+	sf := snowflake.New()
+
+	// 超过单毫秒 4096 的容量，批量内必然跨越毫秒
+	ids, err := sf.NextIDs(10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 10000 {
+		t.Fatalf("len(ids) = %d, want 10000", len(ids))
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Fatalf("ids[%d] = %d is not greater than ids[%d] = %d", i, ids[i], i-1, ids[i-1])
+		}
+	}
+
+	if next := sf.MustNextID(); next <= ids[len(ids)-1] {
+		t.Errorf("NextID after batch = %d, want greater than %d", next, ids[len(ids)-1])
+	}
+
+	if ids, _ := sf.NextIDs(0); len(ids) != 0 {
+		t.Errorf("NextIDs(0) returned %d ids", len(ids))
+	}
+}
+
+func TestFillIDs(t *testing.T) {
+	sf := snowflake.New()
+
+	// 两次填充共 10000 个，跨越多个毫秒，且第二次与第一次的结果也要递增
+	buf := make([]int64, 5000)
+	var prev int64
+	for round := 0; round < 2; round++ {
+		if n := sf.FillIDs(buf); n != len(buf) {
+			t.Fatalf("FillIDs() = %d, want %d", n, len(buf))
+		}
+		for i, id := range buf {
+			if id <= prev {
+				t.Fatalf("round %d: buf[%d] = %d is not greater than %d", round, i, id, prev)
+			}
+			prev = id
+		}
+	}
+
+	if n := sf.FillIDs(nil); n != 0 {
+		t.Errorf("FillIDs(nil) = %d, want 0", n)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { sf.FillIDs(buf[:100]) }); allocs != 0 {
+		t.Errorf("FillIDs allocates %.1f times per run, want 0", allocs)
+	}
+}
+
+func BenchmarkFillIDs(b *testing.B) {
+	sf := snowflake.New()
+	buf := make([]int64, 256)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sf.FillIDs(buf)
+	}
+}
+
+func TestNextIDContext(t *testing.T) {
+	sf := snowflake.New()
+
+	id, err := sf.NextIDContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// 已取消的 ctx 只在需要等待下一毫秒时才会生效，所有成功返回的 ID 仍需严格递增
+	for i := 0; i < 10000; i++ {
+		next, err := sf.NextIDContext(ctx)
+		if err != nil {
+			if err != context.Canceled {
+				t.Fatalf("err = %v, want context.Canceled", err)
+			}
+			continue
+		}
+		if next <= id {
+			t.Fatalf("id %d is not greater than previous %d", next, id)
+		}
+		id = next
+	}
+}
+
+// fakeClock 依次返回 times 中的时间，用完后一直返回最后一个
+type fakeClock struct {
+	mu    sync.Mutex
+	times []time.Time
+}
+
+func newFakeClock(times ...time.Time) *fakeClock {
+	return &fakeClock{times: times}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.times[0]
+	if len(c.times) > 1 {
+		c.times = c.times[1:]
+	}
+	return now
+}
+
+func repeatTime(t time.Time, n int) []time.Time {
+	times := make([]time.Time, n)
+	for i := range times {
+		times[i] = t
+	}
+	return times
+}
+
+var (
+	testStartTime = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	testNow       = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestNextIDSameMillisecond(t *testing.T) {
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(testNow), 1, 2)
+
+	for i := 0; i < 3; i++ {
+		_, _, _, sequence := snowflake.ParseID(sf.MustNextID(), testStartTime)
+		if sequence != int16(i) {
+			t.Errorf("sequence = %d, want %d", sequence, i)
+		}
+	}
+}
+
+func TestNextIDSequenceExhausted(t *testing.T) {
+	// 4096 个 ID 用完当前毫秒的序号，第 4097 个需要等到下一毫秒
+	times := append(repeatTime(testNow, 4097), testNow.Add(time.Millisecond))
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(times...), 1, 2)
+
+	var id int64
+	for i := 0; i < 4097; i++ {
+		id = sf.MustNextID()
+	}
+
+	elapsedMs, _, _, sequence := snowflake.ParseID(id, testStartTime)
+	wantElapsed := testNow.Sub(testStartTime).Milliseconds() + 1
+	if elapsedMs != wantElapsed || sequence != 0 {
+		t.Errorf("elapsedMs, sequence = %d, %d, want %d, 0", elapsedMs, sequence, wantElapsed)
+	}
+}
+
+func TestNextIDClockBackwards(t *testing.T) {
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(testNow, testNow.Add(-5*time.Second)), 1, 2)
+
+	if _, err := sf.NextID(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := sf.NextID()
+	var backwardsErr *snowflake.ClockBackwardsError
+	if !errors.As(err, &backwardsErr) {
+		t.Fatalf("err = %v, want *ClockBackwardsError", err)
+	}
+	if backwardsErr.Delta != 5000 {
+		t.Errorf("Delta = %d, want 5000", backwardsErr.Delta)
+	}
+}
+
+func TestStartTimeTruncated(t *testing.T) {
+	// 开始时间带有亚毫秒的部分，构造时截断到毫秒
+	startTime := testStartTime.Add(123456789 * time.Nanosecond)
+	now := testNow.Add(987654321 * time.Nanosecond)
+	sf := snowflake.NewWithClock(startTime, newFakeClock(now), 1, 2)
+
+	if want := testStartTime.Add(123 * time.Millisecond); !sf.StartTime().Equal(want) {
+		t.Errorf("StartTime() = %s, want %s", sf.StartTime(), want)
+	}
+
+	id := sf.MustNextID()
+	if got, want := sf.TimeOf(id), now.Truncate(time.Millisecond); !got.Equal(want) {
+		t.Errorf("TimeOf(id) = %s, want %s", got, want)
+	}
+	if got, want := sf.TimestampMsOf(id), now.UnixMilli(); got != want {
+		t.Errorf("TimestampMsOf(id) = %d, want %d", got, want)
+	}
+	if elapsedMs, _, _, _ := snowflake.ParseID(id, sf.StartTime()); elapsedMs != now.UnixMilli()-sf.StartTime().UnixMilli() {
+		t.Errorf("ParseID elapsedMs = %d, want %d", elapsedMs, now.UnixMilli()-sf.StartTime().UnixMilli())
+	}
+}
+
+func TestNextIDLeapSecond(t *testing.T) {
+	// 闰秒让时钟回跳 500ms，之后逐渐走回 testNow 并继续前进；默认的 BackwardsError 和 BackwardsPanic 都应等待而不是报错
+	for _, policy := range []snowflake.ClockBackwardsPolicy{snowflake.BackwardsError, snowflake.BackwardsPanic} {
+		times := repeatTime(testNow, 10)
+		for d := -500 * time.Millisecond; d <= 5*time.Millisecond; d += time.Millisecond {
+			times = append(times, testNow.Add(d))
+		}
+		sf := snowflake.NewWithClock(testStartTime, newFakeClock(times...), 1, 2)
+		sf.SetClockBackwardsPolicy(policy)
+
+		seen := make(map[int64]bool)
+		var prev int64
+		for i := 0; i < 20; i++ {
+			id, err := sf.NextID()
+			if err != nil {
+				t.Fatalf("policy %d: NextID() error: %v", policy, err)
+			}
+			if seen[id] || id <= prev {
+				t.Fatalf("policy %d: id %d is duplicate or not greater than %d", policy, id, prev)
+			}
+			seen[id], prev = true, id
+		}
+		if got := sf.Stats().ClockBackwards; got == 0 {
+			t.Errorf("policy %d: ClockBackwards = 0, want the leap second counted", policy)
+		}
+	}
+}
+
+func TestNextIDBackwardTolerance(t *testing.T) {
+	clock := newFakeClock(
+		testNow,
+		testNow.Add(-5*time.Millisecond),
+		testNow.Add(-2*time.Millisecond),
+		testNow,
+	)
+	sf := snowflake.NewWithClock(testStartTime, clock, 1, 2)
+	sf.SetMaxBackwardTolerance(10 * time.Millisecond)
+
+	first, err := sf.NextID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := sf.NextID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second <= first {
+		t.Errorf("second id %d is not greater than first %d", second, first)
+	}
+}
+
+func TestNewWithStrict(t *testing.T) {
+	sf, err := snowflake.NewWithStrict(testStartTime, 31, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	if sf.DataCenterID() != 31 || sf.WorkerID() != 0 {
+		t.Errorf("DataCenterID, WorkerID = %d, %d, want 31, 0", sf.DataCenterID(), sf.WorkerID())
+	}
+
+	if _, err := snowflake.NewWithStrict(testStartTime, 40, 1); err == nil {
+		t.Error("dataCenterID 40 should be rejected")
+	}
+	if _, err := snowflake.NewWithStrict(testStartTime, 1, 32); err == nil {
+		t.Error("workerID 32 should be rejected")
+	}
+}
+
+func TestNewWithEpochMs(t *testing.T) {
+	// 2020-01-01T00:00:00Z
+	sf, err := snowflake.NewWithEpochMs(1577836800000, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sf.StartTime(); !got.Equal(testStartTime) || got.Location() != time.UTC {
+		t.Errorf("StartTime = %s, want %s", got, testStartTime)
+	}
+
+	if _, err := snowflake.NewWithEpochMs(time.Now().Add(time.Hour).UnixNano()/1e6, 1, 2); !errors.Is(err, snowflake.ErrFutureStartTime) {
+		t.Errorf("err = %v, want ErrFutureStartTime", err)
+	}
+}
+
+func TestNewUsesDefaultEpoch(t *testing.T) {
+	if got := snowflake.New().StartTime(); !got.Equal(snowflake.DefaultEpoch) {
+		t.Errorf("StartTime = %s, want %s", got, snowflake.DefaultEpoch)
+	}
+
+	// 两个 New 出来的生成器（模拟重启）生成的 ID 仍然递增
+	first := snowflake.New().MustNextID()
+	time.Sleep(2 * time.Millisecond)
+	if second := snowflake.New().MustNextID(); second <= first {
+		t.Errorf("id after restart %d is not greater than %d", second, first)
+	}
+}
+
+func TestNextIDConcurrent(t *testing.T) {
+	sf := snowflake.New()
+
+	const goroutines, perGoroutine = 16, 2000
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		ids = make(map[int64]struct{}, goroutines*perGoroutine)
+	)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				id := sf.MustNextID()
+				mu.Lock()
+				ids[id] = struct{}{}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(ids) != goroutines*perGoroutine {
+		t.Errorf("got %d unique ids, want %d", len(ids), goroutines*perGoroutine)
+	}
+}
+
+func TestNextIDTimestampOverflow(t *testing.T) {
+	maxElapsed := time.Duration(1<<41-1) * time.Millisecond
+
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(testStartTime.Add(maxElapsed), testStartTime.Add(maxElapsed+time.Millisecond)), 1, 2)
+	id, err := sf.NextID()
+	if err != nil {
+		t.Fatalf("last representable millisecond: %v", err)
+	}
+	if elapsedMs, _, _, _ := snowflake.ParseID(id, testStartTime); elapsedMs != 1<<41-1 {
+		t.Errorf("elapsedMs = %d, want %d", elapsedMs, int64(1<<41-1))
+	}
+
+	if _, err := sf.NextID(); !errors.Is(err, snowflake.ErrTimestampOverflow) {
+		t.Errorf("err = %v, want ErrTimestampOverflow", err)
+	}
+	if got := sf.RemainingLifetime(); got != 0 {
+		t.Errorf("RemainingLifetime = %s, want 0", got)
+	}
+}
+
+func TestNextIDsTimestampOverflow(t *testing.T) {
+	// 最后一个可表示的毫秒只剩 4096 个序号，批量在中途越过时间戳的上限
+	last := testStartTime.Add(time.Duration(1<<41-1) * time.Millisecond)
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(append(repeatTime(last, 4096), last.Add(time.Millisecond))...), 1, 2)
+
+	ids, err := sf.NextIDs(5000)
+	if !errors.Is(err, snowflake.ErrTimestampOverflow) {
+		t.Fatalf("err = %v, want ErrTimestampOverflow", err)
+	}
+	if !strings.Contains(err.Error(), "generated 4096 of 5000") {
+		t.Errorf("err = %v, want it to report 4096 of 5000 generated", err)
+	}
+	if len(ids) != 4096 {
+		t.Fatalf("len(ids) = %d, want 4096", len(ids))
+	}
+	for i, id := range ids {
+		if elapsedMs, _, _, sequence := snowflake.ParseID(id, testStartTime); elapsedMs != 1<<41-1 || sequence != int16(i) {
+			t.Fatalf("ids[%d] = elapsed %d sequence %d, want %d, %d", i, elapsedMs, sequence, int64(1<<41-1), i)
+		}
+	}
+}
+
+func TestRemainingLifetime(t *testing.T) {
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(testStartTime.Add(time.Hour)), 1, 2)
+
+	want := time.Duration(1<<41-1)*time.Millisecond - time.Hour
+	if got := sf.RemainingLifetime(); got != want {
+		t.Errorf("RemainingLifetime = %s, want %s", got, want)
+	}
+
+	// 时间戳的范围超出 time.Duration 时取最大值，而不是溢出
+	cfg := snowflake.Config{TimestampBits: 55, DataCenterBits: 2, WorkerBits: 2, SequenceBits: 4}
+	sf, err := snowflake.NewWithOptions(
+		snowflake.WithConfig(cfg),
+		snowflake.WithStartTime(testStartTime),
+		snowflake.WithClock(newFakeClock(testNow)),
+		snowflake.WithDataCenterID(1),
+		snowflake.WithWorkerID(2),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sf.Close()
+	if got := sf.RemainingLifetime(); got != time.Duration(math.MaxInt64) {
+		t.Errorf("RemainingLifetime with 55 timestamp bits = %s, want %s", got, time.Duration(math.MaxInt64))
+	}
+}
+
+func TestRemainingInMillis(t *testing.T) {
+	clock := newFakeClock(append(repeatTime(testNow, 12), testNow.Add(time.Millisecond))...)
+	sf := snowflake.NewWithClock(testStartTime, clock, 1, 2)
+
+	// 还没生成过 ID
+	if got := sf.RemainingInMillis(); got != 4096 {
+		t.Errorf("RemainingInMillis = %d, want 4096", got)
+	}
+
+	for i := 0; i < 10; i++ {
+		sf.MustNextID()
+	}
+	if got := sf.RemainingInMillis(); got != 4086 {
+		t.Errorf("RemainingInMillis = %d, want 4086", got)
+	}
+
+	// 进入下一毫秒
+	if got := sf.RemainingInMillis(); got != 4096 {
+		t.Errorf("RemainingInMillis after rollover = %d, want 4096", got)
+	}
+
+	// 时钟回拨时下一个 ID 不会进入新的毫秒，剩余的仍是上次时间戳的序号
+	clock = newFakeClock(append(repeatTime(testNow, 10), testNow.Add(-2*time.Second))...)
+	sf = snowflake.NewWithClock(testStartTime, clock, 1, 2)
+	for i := 0; i < 10; i++ {
+		sf.MustNextID()
+	}
+	if got := sf.RemainingInMillis(); got != 4086 {
+		t.Errorf("RemainingInMillis after clock moved backwards = %d, want 4086", got)
+	}
+}
+
+func TestNextIDJitteryClock(t *testing.T) {
+	ms := func(n int) time.Time { return testNow.Add(time.Duration(n) * time.Millisecond) }
+	// 时钟先向前跳，再被校正回来，在容忍范围内来回抖动
+	clock := newFakeClock(ms(0), ms(1), ms(8), ms(3), ms(5), ms(8), ms(7), ms(9), ms(2), ms(10))
+	sf := snowflake.NewWithClock(testStartTime, clock, 1, 2)
+	sf.SetMaxBackwardTolerance(10 * time.Millisecond)
+
+	var prev, prevElapsed int64
+	for i := 0; i < 10; i++ {
+		id, err := sf.NextID()
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if id <= prev {
+			t.Fatalf("#%d: id %d is not greater than previous %d", i, id, prev)
+		}
+
+		elapsedMs, _, _, sequence := snowflake.ParseID(id, testStartTime)
+		if elapsedMs < prevElapsed {
+			t.Fatalf("#%d: embedded timestamp went backwards", i)
+		}
+		// 时间戳前进时序号重置
+		if elapsedMs > prevElapsed && sequence != 0 {
+			t.Errorf("#%d: sequence = %d after the timestamp advanced, want 0", i, sequence)
+		}
+		prev, prevElapsed = id, elapsedMs
+	}
+}
+
+func TestFutureStartTime(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+
+	if _, err := snowflake.NewWithStrict(future, 1, 2); !errors.Is(err, snowflake.ErrFutureStartTime) {
+		t.Errorf("NewWithStrict err = %v, want ErrFutureStartTime", err)
+	}
+	if _, err := snowflake.NewWithOptions(snowflake.WithStartTime(future), snowflake.WithDataCenterID(1), snowflake.WithWorkerID(2)); !errors.Is(err, snowflake.ErrFutureStartTime) {
+		t.Errorf("NewWithOptions err = %v, want ErrFutureStartTime", err)
+	}
+
+	// 宽松的构造函数不校验，但生成 ID 时会报错而不是生成错误的 ID
+	sf := snowflake.NewWith(future, 1, 2)
+	if _, err := sf.NextID(); !errors.Is(err, snowflake.ErrFutureStartTime) {
+		t.Errorf("NextID err = %v, want ErrFutureStartTime", err)
+	}
+}
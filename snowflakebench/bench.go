@@ -0,0 +1,64 @@
+// Package snowflakebench 提供在使用方的机器上测量 snowflake 生成器并发性能的辅助函数，
+// 单独成包，主包不会因此引入测试相关的依赖
+package snowflakebench
+
+import (
+	"sync"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+// BenchmarkConcurrent 启动 goroutines 个 goroutine，每个通过 sf 调用 perGoroutine 次 NextID，
+// 返回成功生成的 ID 中重复的个数、出错的调用次数和从开始生成到全部生成完的耗时；同时也是对生成器锁和原子操作实现的压力测试
+// sf 可以是 *snowflake.SnowFlake、*snowflake.AtomicSnowFlake 等任意 Generator，各 goroutine 之间不共享状态，
+// 只在结束后合并结果，与 errgroup 等并发工具一起使用也是安全的
+// 生成 ID 出错（如时钟回拨超过一秒、生成器已关闭）时不中断，只计入 failed，可以通过 b.ReportMetric 报告或者在 failed 不为 0 时 b.Error
+func BenchmarkConcurrent(sf snowflake.Generator, goroutines, perGoroutine int) (dupes, failed int, elapsed time.Duration) {
+	results := make([][]int64, goroutines)
+	failures := make([]int, goroutines)
+
+	var (
+		wg    sync.WaitGroup
+		start = make(chan struct{})
+	)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			ids := make([]int64, 0, perGoroutine)
+			<-start
+			for i := 0; i < perGoroutine; i++ {
+				id, err := sf.NextID()
+				if err != nil {
+					failures[g]++
+					continue
+				}
+				ids = append(ids, id)
+			}
+			results[g] = ids
+		}(g)
+	}
+
+	// 所有 goroutine 就绪后同时开始，不把创建 goroutine 和分配内存的时间计入耗时
+	begin := time.Now()
+	close(start)
+	wg.Wait()
+	elapsed = time.Since(begin)
+
+	for _, n := range failures {
+		failed += n
+	}
+
+	seen := make(map[int64]struct{}, goroutines*perGoroutine)
+	for _, ids := range results {
+		for _, id := range ids {
+			if _, ok := seen[id]; ok {
+				dupes++
+				continue
+			}
+			seen[id] = struct{}{}
+		}
+	}
+	return dupes, failed, elapsed
+}
@@ -0,0 +1,51 @@
+package snowflakebench_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+	"github.com/polaris1119/snowflake/snowflakebench"
+)
+
+var testStartTime = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestBenchmarkConcurrent(t *testing.T) {
+	for name, sf := range map[string]snowflake.Generator{
+		"mutex":  snowflake.NewWith(testStartTime, 1, 2),
+		"atomic": snowflake.NewAtomic(testStartTime, 1, 2),
+	} {
+		dupes, failed, elapsed := snowflakebench.BenchmarkConcurrent(sf, 8, 5000)
+		if dupes != 0 {
+			t.Errorf("%s: %d duplicate ids", name, dupes)
+		}
+		if failed != 0 {
+			t.Errorf("%s: %d failed calls", name, failed)
+		}
+		if elapsed <= 0 {
+			t.Errorf("%s: elapsed = %s, want positive", name, elapsed)
+		}
+	}
+
+	// 重复的 ID 被计数
+	if dupes, _, _ := snowflakebench.BenchmarkConcurrent(snowflake.NewMockGenerator(1, 2, 2, 1), 2, 2); dupes != 2 {
+		t.Errorf("dupes = %d, want 2", dupes)
+	}
+}
+
+func TestBenchmarkConcurrentError(t *testing.T) {
+	mock := snowflake.NewMockGenerator()
+	mock.SetError(errors.New("boom"))
+
+	// 出错的调用只计数，不 panic
+	if dupes, failed, _ := snowflakebench.BenchmarkConcurrent(mock, 4, 10); dupes != 0 || failed != 40 {
+		t.Errorf("dupes, failed = %d, %d, want 0, 40", dupes, failed)
+	}
+
+	sf := snowflake.NewWith(testStartTime, 1, 2)
+	sf.Close()
+	if _, failed, _ := snowflakebench.BenchmarkConcurrent(sf, 2, 5); failed != 10 {
+		t.Errorf("failed after Close = %d, want 10", failed)
+	}
+}
@@ -0,0 +1,70 @@
+// Package snowflakehttp 通过 HTTP 提供 snowflake ID，供非 Go 的服务使用
+package snowflakehttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/polaris1119/snowflake"
+)
+
+// MaxCount 一次请求最多返回的 ID 数，count 超过时按 MaxCount 返回
+const MaxCount = 1000
+
+// Handler 返回一个 http.Handler，只接受 GET 请求：
+//
+//	GET /          → {"id":"<decimal>"}
+//	GET /?count=N  → {"ids":["<decimal>", ...]}
+//
+// ID 以字符串形式输出，避免 JavaScript 客户端丢失精度；批量时通过 NextIDs 一次生成
+func Handler(sf *snowflake.SnowFlake) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+		if _, ok := query["count"]; !ok {
+			id, err := sf.NextID()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			writeJSON(w, struct {
+				ID snowflake.ID `json:"id"`
+			}{snowflake.ID(id)})
+			return
+		}
+
+		count, err := strconv.Atoi(query.Get("count"))
+		if err != nil || count <= 0 {
+			http.Error(w, "snowflakehttp: count must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		if count > MaxCount {
+			count = MaxCount
+		}
+
+		ids, err := sf.NextIDs(count)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		typed := make([]snowflake.ID, len(ids))
+		for i, id := range ids {
+			typed[i] = snowflake.ID(id)
+		}
+		writeJSON(w, struct {
+			IDs []snowflake.ID `json:"ids"`
+		}{typed})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(v)
+}
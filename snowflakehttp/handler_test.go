@@ -0,0 +1,86 @@
+package snowflakehttp_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+	"github.com/polaris1119/snowflake/snowflakehttp"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	sf := snowflake.NewWith(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), 1, 2)
+	srv := httptest.NewServer(snowflakehttp.Handler(sf))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func get(t *testing.T, url string, v interface{}) int {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestHandlerSingle(t *testing.T) {
+	srv := newServer(t)
+
+	var body map[string]string
+	if code := get(t, srv.URL, &body); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if _, err := strconv.ParseInt(body["id"], 10, 64); err != nil {
+		t.Errorf("id = %q, want a decimal string", body["id"])
+	}
+}
+
+func TestHandlerCount(t *testing.T) {
+	srv := newServer(t)
+
+	var body struct {
+		IDs []string `json:"ids"`
+	}
+	if code := get(t, srv.URL+"?count=5", &body); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if len(body.IDs) != 5 {
+		t.Fatalf("len(ids) = %d, want 5", len(body.IDs))
+	}
+
+	body.IDs = nil
+	get(t, srv.URL+"?count="+strconv.Itoa(snowflakehttp.MaxCount+1), &body)
+	if len(body.IDs) != snowflakehttp.MaxCount {
+		t.Errorf("len(ids) = %d, want capped at %d", len(body.IDs), snowflakehttp.MaxCount)
+	}
+
+	for _, q := range []string{"?count=0", "?count=-1", "?count=abc"} {
+		if code := get(t, srv.URL+q, nil); code != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want 400", q, code)
+		}
+	}
+}
+
+func TestHandlerMethod(t *testing.T) {
+	srv := newServer(t)
+
+	resp, err := http.Post(srv.URL, "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want 405", resp.StatusCode)
+	}
+}
@@ -0,0 +1,56 @@
+// Package snowflakeprom 将 SnowFlake 的计数暴露为 Prometheus 指标
+// 单独作为一个 module，只使用 snowflake 的 ID 而不需要指标的项目不会依赖 Prometheus
+package snowflakeprom
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/polaris1119/snowflake"
+)
+
+// collector 将 SnowFlake.Stats 的计数暴露为 Prometheus 指标
+type collector struct {
+	s *snowflake.SnowFlake
+
+	generated         *prometheus.Desc
+	sequenceExhausted *prometheus.Desc
+	clockBackwards    *prometheus.Desc
+}
+
+// NewCollector 返回暴露 s 生成计数的 prometheus.Collector
+// 指标带有 data_center 和 worker 标签，可以同时注册多个 SnowFlake 的 Collector：
+//   - snowflake_ids_generated_total：成功生成的 ID 数
+//   - snowflake_sequence_exhausted_total：当前毫秒内序号用完、需要等待下一毫秒的次数
+//   - snowflake_clock_backwards_total：检测到时钟回拨的次数
+func NewCollector(s *snowflake.SnowFlake) prometheus.Collector {
+	labels := prometheus.Labels{
+		"data_center": strconv.Itoa(int(s.DataCenterID())),
+		"worker":      strconv.Itoa(int(s.WorkerID())),
+	}
+
+	return &collector{
+		s: s,
+
+		generated: prometheus.NewDesc("snowflake_ids_generated_total",
+			"Number of ids generated.", nil, labels),
+		sequenceExhausted: prometheus.NewDesc("snowflake_sequence_exhausted_total",
+			"Number of times the per-millisecond sequence was exhausted and generation waited for the next millisecond.", nil, labels),
+		clockBackwards: prometheus.NewDesc("snowflake_clock_backwards_total",
+			"Number of times the clock was observed moving backwards.", nil, labels),
+	}
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.generated
+	ch <- c.sequenceExhausted
+	ch <- c.clockBackwards
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.s.Stats()
+	ch <- prometheus.MustNewConstMetric(c.generated, prometheus.CounterValue, float64(stats.Generated))
+	ch <- prometheus.MustNewConstMetric(c.sequenceExhausted, prometheus.CounterValue, float64(stats.SequenceExhaustedWaits))
+	ch <- prometheus.MustNewConstMetric(c.clockBackwards, prometheus.CounterValue, float64(stats.ClockBackwards))
+}
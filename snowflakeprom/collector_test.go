@@ -0,0 +1,47 @@
+package snowflakeprom_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/polaris1119/snowflake"
+	"github.com/polaris1119/snowflake/clocktest"
+	"github.com/polaris1119/snowflake/snowflakeprom"
+)
+
+func TestNewCollector(t *testing.T) {
+	startTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := clocktest.New(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	sf := snowflake.NewWithClock(startTime, clock, 1, 2)
+	sf.MustNextID()
+	sf.MustNextID()
+	clock.SetBackward(2 * time.Second)
+	sf.NextID()
+
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(snowflakeprom.NewCollector(sf)); err != nil {
+		t.Fatal(err)
+	}
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]float64{
+		"snowflake_ids_generated_total":      2,
+		"snowflake_sequence_exhausted_total": 0,
+		"snowflake_clock_backwards_total":    1,
+	}
+	for _, family := range families {
+		got := family.GetMetric()[0].GetCounter().GetValue()
+		if got != want[family.GetName()] {
+			t.Errorf("%s = %v, want %v", family.GetName(), got, want[family.GetName()])
+		}
+		delete(want, family.GetName())
+	}
+	if len(want) != 0 {
+		t.Errorf("missing metrics: %v", want)
+	}
+}
@@ -0,0 +1,23 @@
+module github.com/polaris1119/snowflake/snowflakeprom
+
+go 1.17
+
+require (
+	github.com/polaris1119/snowflake v0.0.0-20261014071048-ccf5c3da5878
+	github.com/prometheus/client_golang v1.14.0
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.1.2 // indirect
+	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/matttproud/golang_protobuf_extensions v1.0.1 // indirect
+	github.com/prometheus/client_model v0.3.0 // indirect
+	github.com/prometheus/common v0.37.0 // indirect
+	github.com/prometheus/procfs v0.8.0 // indirect
+	golang.org/x/sys v0.0.0-20220520151302-bc2c85ada10a // indirect
+	google.golang.org/protobuf v1.28.1 // indirect
+)
+
+// 仓库内开发和测试时使用同一仓库中的 snowflake；使用者会忽略 replace，按上面 require 的版本解析
+replace github.com/polaris1119/snowflake => ../
@@ -0,0 +1,69 @@
+// Package snowflaketest 提供在使用方的测试中校验 snowflake 配置的辅助函数
+package snowflaketest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/polaris1119/snowflake"
+)
+
+// maxReported 错误信息中最多列出的重复 ID 数
+const maxReported = 10
+
+// AssertNoCollisions 让每个生成器并发生成 perGen 个 ID，存在重复时返回列出重复 ID 的错误
+// 可以发现多个生成器误用了相同节点 ID 的配置；生成 ID 出错时返回该错误
+func AssertNoCollisions(gens []*snowflake.SnowFlake, perGen int) error {
+	results := make([][]int64, len(gens))
+	errs := make([]error, len(gens))
+
+	var wg sync.WaitGroup
+	for i, sf := range gens {
+		wg.Add(1)
+		go func(i int, sf *snowflake.SnowFlake) {
+			defer wg.Done()
+			ids := make([]int64, 0, perGen)
+			for j := 0; j < perGen; j++ {
+				id, err := sf.NextID()
+				if err != nil {
+					errs[i] = fmt.Errorf("snowflaketest: generator %d (%s): %w", i, sf, err)
+					return
+				}
+				ids = append(ids, id)
+			}
+			results[i] = ids
+		}(i, sf)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	// ID 第一次出现时所在的生成器
+	owner := make(map[int64]int, len(gens)*perGen)
+	var (
+		dups  []string
+		total int
+	)
+	for i, ids := range results {
+		for _, id := range ids {
+			first, ok := owner[id]
+			if !ok {
+				owner[id] = i
+				continue
+			}
+			total++
+			if len(dups) < maxReported {
+				dups = append(dups, fmt.Sprintf("%d (generators %d and %d)", id, first, i))
+			}
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+	return fmt.Errorf("snowflaketest: %d duplicate ids: %s", total, strings.Join(dups, ", "))
+}
@@ -0,0 +1,43 @@
+package snowflaketest_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+	"github.com/polaris1119/snowflake/snowflaketest"
+)
+
+var startTime = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestAssertNoCollisions(t *testing.T) {
+	gens := []*snowflake.SnowFlake{
+		snowflake.NewWith(startTime, 0, 0),
+		snowflake.NewWith(startTime, 0, 1),
+		snowflake.NewWith(startTime, 1, 0),
+	}
+	if err := snowflaketest.AssertNoCollisions(gens, 5000); err != nil {
+		t.Errorf("AssertNoCollisions() = %v, want nil", err)
+	}
+}
+
+func TestAssertNoCollisionsDuplicateNode(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	gens := []*snowflake.SnowFlake{
+		snowflake.NewWithClock(startTime, fixedClock(now), 3, 7),
+		snowflake.NewWithClock(startTime, fixedClock(now), 3, 7),
+	}
+
+	err := snowflaketest.AssertNoCollisions(gens, 100)
+	if err == nil {
+		t.Fatal("generators with the same node id should collide")
+	}
+	if !strings.Contains(err.Error(), "100 duplicate ids") {
+		t.Errorf("err = %v, want it to report 100 duplicates", err)
+	}
+}
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
@@ -0,0 +1,75 @@
+package snowflake
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// stateSize MarshalState 的长度：8 字节的上次时间戳和 8 字节的序号
+const stateSize = 16
+
+// MarshalState 序列化上次生成 ID 的时间戳和序号，用于持久化后在重启时通过 RestoreState 恢复
+func (s *SnowFlake) MarshalState() []byte {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	b := make([]byte, stateSize)
+	binary.BigEndian.PutUint64(b, uint64(s.lastTimestamp))
+	binary.BigEndian.PutUint64(b[8:], uint64(s.sequence))
+	return b
+}
+
+// RestoreState 恢复 MarshalState 保存的状态，保证重启后不会生成重复的 ID
+// 保存的时间戳晚于当前时间（如重启后时钟还没走过上次的时间戳）时，NextID 会沿用保存的时间戳继续递增序号，
+// 序号用完时等待时钟追上，而不是报时钟回拨
+func (s *SnowFlake) RestoreState(b []byte) error {
+	if len(b) != stateSize {
+		return fmt.Errorf("snowflake: invalid state length %d, want %d", len(b), stateSize)
+	}
+
+	lastTimestamp := int64(binary.BigEndian.Uint64(b))
+	sequence := int64(binary.BigEndian.Uint64(b[8:]))
+	if lastTimestamp < 0 {
+		return fmt.Errorf("snowflake: invalid last timestamp %d in state", lastTimestamp)
+	}
+	if sequence < 0 || sequence > s.layout.sequenceMask {
+		return fmt.Errorf("snowflake: sequence %d in state out of range [0, %d]", sequence, s.layout.sequenceMask)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// 不允许恢复到比当前更早的状态
+	if lastTimestamp < s.lastTimestamp || lastTimestamp == s.lastTimestamp && sequence < s.sequence {
+		return errors.New("snowflake: state is older than the generator's current state")
+	}
+
+	s.lastTimestamp = lastTimestamp
+	s.sequence = sequence
+	s.restored = true
+	return nil
+}
+
+// Reset 清空上次的时间戳和序号、RestoreState 恢复的状态以及 GenerateAt 记录的序号，相当于重新创建生成器
+// 在运行中的生成器上调用时，如果时钟还没走过之前的时间戳，会生成重复的 ID，只应在测试中使用
+func (s *SnowFlake) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.lastTimestamp = 0
+	s.sequence = 0
+	s.restored = false
+	s.backfill = nil
+	s.backfillLatest = 0
+	s.backfillFloor = 0
+}
+
+// setStateForTest 直接设置上次的时间戳（Unix 毫秒，使用微秒时间戳时为微秒）和序号，用于测试序号用完等边界情况
+func (s *SnowFlake) setStateForTest(ts int64, seq int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.lastTimestamp = ts
+	s.sequence = seq
+}
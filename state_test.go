@@ -0,0 +1,88 @@
+package snowflake_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestRestoreState(t *testing.T) {
+	old := snowflake.NewWithClock(testStartTime, newFakeClock(testNow), 1, 2)
+	var last int64
+	for i := 0; i < 3; i++ {
+		last = old.MustNextID()
+	}
+	state := old.MarshalState()
+
+	// 重启后时钟落后于保存的时间戳 50ms，NextID 需要等待而不是报错
+	clock := newFakeClock(append(repeatTime(testNow.Add(-50*time.Millisecond), 5), testNow)...)
+	sf := snowflake.NewWithClock(testStartTime, clock, 1, 2)
+	if err := sf.RestoreState(state); err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := sf.NextID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id <= last {
+		t.Errorf("id after restore %d is not greater than last id %d", id, last)
+	}
+
+	// 恢复的状态被新时间戳取代后，超出容忍范围的回拨仍会报错
+	sf = snowflake.NewWithClock(testStartTime, newFakeClock(testNow, testNow.Add(time.Millisecond), testNow.Add(-2*time.Second)), 1, 2)
+	if err := sf.RestoreState(state); err != nil {
+		t.Fatal(err)
+	}
+	sf.MustNextID()
+	sf.MustNextID()
+	if _, err := sf.NextID(); err == nil {
+		t.Error("clock moving backwards after restore should return an error")
+	}
+}
+
+func TestRestoreStateInvalid(t *testing.T) {
+	sf := snowflake.NewWith(testStartTime, 1, 2)
+	if err := sf.RestoreState([]byte{1, 2, 3}); err == nil {
+		t.Error("short state should be rejected")
+	}
+
+	state := make([]byte, 16)
+	state[8] = 0xFF
+	if err := sf.RestoreState(state); err == nil {
+		t.Error("out-of-range sequence should be rejected")
+	}
+}
+
+func TestReset(t *testing.T) {
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(testNow, testNow.Add(-time.Hour)), 1, 2)
+	sf.MustNextID()
+
+	sf.Reset()
+	if state := sf.MarshalState(); string(state) != string(make([]byte, 16)) {
+		t.Errorf("MarshalState() after Reset = %v, want all zero", state)
+	}
+	// 重置后不再与之前的时间戳比较，不会报时钟回拨
+	if _, err := sf.NextID(); err != nil {
+		t.Errorf("NextID() after Reset error = %v", err)
+	}
+}
+
+func TestSequenceWrapsAtBoundary(t *testing.T) {
+	next := testNow.Add(time.Millisecond)
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(testNow, next), 1, 2)
+	snowflake.SetStateForTest(sf, testNow.UnixNano()/1e6, 4095)
+
+	// 当前毫秒的序号已经用到 4095，下一个 ID 进入下一毫秒，序号从 0 开始
+	id := sf.MustNextID()
+	if got := sf.TimeOf(id); !got.Equal(next) {
+		t.Errorf("TimeOf = %s, want %s", got, next)
+	}
+	if _, _, _, sequence := snowflake.ParseID(id, testStartTime); sequence != 0 {
+		t.Errorf("sequence = %d, want 0", sequence)
+	}
+	if got := sf.Stats().SequenceExhaustedWaits; got != 1 {
+		t.Errorf("SequenceExhaustedWaits = %d, want 1", got)
+	}
+}
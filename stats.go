@@ -0,0 +1,23 @@
+package snowflake
+
+import "sync/atomic"
+
+// Stats 生成器自创建以来的计数
+type Stats struct {
+	// 成功生成的 ID 数
+	Generated uint64
+	// 当前毫秒内序号用完、需要等待下一毫秒的次数，持续增长说明节点达到了吞吐上限
+	SequenceExhaustedWaits uint64
+	// 检测到时钟回拨的次数，包括容忍范围内的回拨
+	ClockBackwards uint64
+}
+
+// Stats 返回当前的计数，不加锁，可以在生成 ID 的同时定期调用
+// 各项计数分别原子读取，彼此之间不保证是同一时刻的快照
+func (s *SnowFlake) Stats() Stats {
+	return Stats{
+		Generated:              atomic.LoadUint64(&s.counters.generated),
+		SequenceExhaustedWaits: atomic.LoadUint64(&s.counters.sequenceExhausted),
+		ClockBackwards:         atomic.LoadUint64(&s.counters.clockBackwards),
+	}
+}
@@ -0,0 +1,28 @@
+package snowflake_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestStats(t *testing.T) {
+	// 4096 个 ID 用完第一毫秒的序号，第 4097 个等到下一毫秒，随后一次容忍范围内的回拨
+	times := append(repeatTime(testNow, 4097), testNow.Add(time.Millisecond), testNow)
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(times...), 1, 2)
+	sf.SetMaxBackwardTolerance(time.Second)
+
+	if got := sf.Stats(); got != (snowflake.Stats{}) {
+		t.Errorf("Stats() before generating = %+v, want zero", got)
+	}
+
+	for i := 0; i < 4098; i++ {
+		sf.MustNextID()
+	}
+
+	want := snowflake.Stats{Generated: 4098, SequenceExhaustedWaits: 1, ClockBackwards: 1}
+	if got := sf.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package snowflake
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// NodeStrategy NewWithStrategy 获取节点 ID 的方式
+type NodeStrategy int
+
+const (
+	// NodeFromIP 哈希本机的 IP 地址，见 IPProvider
+	NodeFromIP NodeStrategy = iota
+	// NodeFromHostname 哈希主机名，见 HostnameProvider
+	NodeFromHostname
+	// NodeFromEnv 读取环境变量 SNOWFLAKE_NODE_ID 中的 10 位节点 ID，见 EnvProvider
+	NodeFromEnv
+	// NodeManual 使用调用方给定的节点 ID
+	NodeManual
+)
+
+// EnvNodeID NodeFromEnv 读取的环境变量
+const EnvNodeID = "SNOWFLAKE_NODE_ID"
+
+// EnvProvider 从名为 p 的环境变量读取十进制的 10 位节点 ID（含义同 NewWithNode），p 为空时读取 EnvNodeID
+// 环境变量未设置、不是数字或超出范围时返回错误，适合由编排系统为每个实例注入节点 ID 的部署
+type EnvProvider string
+
+// MachineID 实现 MachineIDProvider
+func (p EnvProvider) MachineID() (uint8, uint8, error) {
+	key := string(p)
+	if key == "" {
+		key = EnvNodeID
+	}
+
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return 0, 0, fmt.Errorf("snowflake: environment variable %s is not set", key)
+	}
+	nodeID, err := strconv.ParseUint(v, 10, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("snowflake: invalid node id %q in %s", v, key)
+	}
+	if err := defaultLayout.checkNodeID(uint16(nodeID)); err != nil {
+		return 0, 0, err
+	}
+
+	dataCenterID, workerID := defaultLayout.splitNode(uint16(nodeID))
+	return dataCenterID, workerID, nil
+}
+
+// NewWithStrategy 给定开始时间，按 strategy 获取节点 ID 创建 SnowFlake，manualNode 只在 NodeManual 时使用
+// 按所选方式获取不到节点 ID 时返回错误，而不是像 New 一样退回其他方式；
+// 与 NewWithNode 一样，同一进程内该节点已被未 Close 的生成器使用时返回错误
+func NewWithStrategy(startTime time.Time, strategy NodeStrategy, manualNode uint16) (*SnowFlake, error) {
+	var p MachineIDProvider
+	switch strategy {
+	case NodeManual:
+		return NewWithNode(startTime, manualNode)
+	case NodeFromIP:
+		p = IPProvider{}
+	case NodeFromHostname:
+		p = HostnameProvider{}
+	case NodeFromEnv:
+		p = EnvProvider("")
+	default:
+		return nil, fmt.Errorf("snowflake: unknown NodeStrategy %d", strategy)
+	}
+
+	dataCenterID, workerID, err := p.MachineID()
+	if err != nil {
+		return nil, err
+	}
+	return NewWithNode(startTime, NodeID(dataCenterID, workerID))
+}
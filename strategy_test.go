@@ -0,0 +1,68 @@
+package snowflake_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestNewWithStrategy(t *testing.T) {
+	sf, err := snowflake.NewWithStrategy(testStartTime, snowflake.NodeManual, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sf.NodeID() != 100 {
+		t.Errorf("NodeID = %d, want 100", sf.NodeID())
+	}
+	sf.Close()
+
+	if _, err := snowflake.NewWithStrategy(testStartTime, snowflake.NodeManual, 1024); err == nil {
+		t.Error("NodeManual with nodeID 1024 should return an error")
+	}
+	if _, err := snowflake.NewWithStrategy(testStartTime, snowflake.NodeStrategy(99), 0); err == nil {
+		t.Error("unknown strategy should return an error")
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skip(err)
+	}
+	sf, err = snowflake.NewWithStrategy(testStartTime, snowflake.NodeFromHostname, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dataCenterID, workerID := snowflake.MachineIDFromString(hostname); sf.DataCenterID() != dataCenterID || sf.WorkerID() != workerID {
+		t.Errorf("node = %d, %d, want %d, %d from hostname", sf.DataCenterID(), sf.WorkerID(), dataCenterID, workerID)
+	}
+	sf.Close()
+}
+
+func TestNodeFromEnv(t *testing.T) {
+	t.Setenv(snowflake.EnvNodeID, "37")
+	sf, err := snowflake.NewWithStrategy(testStartTime, snowflake.NodeFromEnv, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sf.NodeID() != 37 {
+		t.Errorf("NodeID = %d, want 37", sf.NodeID())
+	}
+	sf.Close()
+
+	for _, v := range []string{"", "abc", "-1", "1024"} {
+		t.Setenv(snowflake.EnvNodeID, v)
+		if _, err := snowflake.NewWithStrategy(testStartTime, snowflake.NodeFromEnv, 0); err == nil {
+			t.Errorf("%s=%q should return an error", snowflake.EnvNodeID, v)
+		}
+	}
+
+	os.Unsetenv(snowflake.EnvNodeID)
+	if _, _, err := (snowflake.EnvProvider("")).MachineID(); err == nil {
+		t.Errorf("unset %s should return an error", snowflake.EnvNodeID)
+	}
+
+	t.Setenv("MY_NODE", "5")
+	if dataCenterID, workerID, err := snowflake.EnvProvider("MY_NODE").MachineID(); err != nil || dataCenterID != 0 || workerID != 5 {
+		t.Errorf("EnvProvider(MY_NODE) = %d, %d, %v, want 0, 5, nil", dataCenterID, workerID, err)
+	}
+}
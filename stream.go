@@ -0,0 +1,52 @@
+package snowflake
+
+import "context"
+
+// Stream 启动一个 goroutine 持续生成 ID 并写入容量为 bufSize 的 channel
+// 调用返回的 cancel 会停止该 goroutine 并关闭 channel，cancel 返回后不会再生成新的 ID，
+// 可以多次调用；生成出错（如时钟回拨）或生成器被 Close 时同样会停止并关闭 channel
+func (s *SnowFlake) Stream(bufSize int) (<-chan int64, func()) {
+	if bufSize < 0 {
+		bufSize = 0
+	}
+
+	ch := make(chan int64, bufSize)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	s.mutex.Lock()
+	if !s.isClosed() {
+		if s.streams == nil {
+			s.streams = make(map[chan struct{}]context.CancelFunc)
+		}
+		s.streams[done] = cancel
+	}
+	s.mutex.Unlock()
+
+	go func() {
+		defer close(done)
+		defer close(ch)
+
+		for ctx.Err() == nil {
+			id, err := s.NextIDContext(ctx)
+			if err != nil {
+				return
+			}
+
+			select {
+			case ch <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, func() {
+		cancel()
+		<-done
+
+		s.mutex.Lock()
+		delete(s.streams, done)
+		s.mutex.Unlock()
+	}
+}
@@ -0,0 +1,66 @@
+package snowflake_test
+
+import (
+	"testing"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestStream(t *testing.T) {
+	sf := snowflake.NewWith(testStartTime, 1, 2)
+	ch, cancel := sf.Stream(16)
+
+	prev := <-ch
+	for i := 0; i < 1000; i++ {
+		id := <-ch
+		if id <= prev {
+			t.Fatalf("id %d is not greater than previous %d", id, prev)
+		}
+		prev = id
+	}
+
+	cancel()
+	// 再次调用不会 panic
+	cancel()
+
+	// cancel 之后 channel 中只剩下已缓冲的 ID，随后被关闭
+	n := 0
+	for range ch {
+		n++
+	}
+	if n > 16 {
+		t.Errorf("got %d ids after cancel, want at most the buffer size 16", n)
+	}
+
+	// cancel 之后生成器仍可正常使用
+	if id := sf.MustNextID(); id <= prev {
+		t.Errorf("id %d is not greater than streamed id %d", id, prev)
+	}
+}
+
+func TestCloseStopsStream(t *testing.T) {
+	sf := snowflake.NewWith(testStartTime, 1, 2)
+	ch, cancel := sf.Stream(4)
+	<-ch
+
+	if err := sf.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for range ch {
+	}
+	// Close 之后 cancel 仍可调用
+	cancel()
+
+	if _, err := sf.NextID(); err != snowflake.ErrClosed {
+		t.Errorf("NextID() after Close error = %v, want ErrClosed", err)
+	}
+	if err := sf.Close(); err != nil {
+		t.Errorf("second Close() = %v", err)
+	}
+
+	// Close 之后启动的 Stream 立即关闭
+	ch, _ = sf.Stream(4)
+	if _, ok := <-ch; ok {
+		t.Error("Stream after Close should be closed")
+	}
+}
@@ -0,0 +1,36 @@
+package snowflake
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errNoTagBits 位分配没有保留标签位
+var errNoTagBits = errors.New("snowflake: layout has no tag bits, set Config.TagBits")
+
+// NextIDTagged 获取一个带标签的 ID，标签放在位分配保留的标签位中（见 Config.TagBits），可以通过 Decompose 取回
+// 位分配没有标签位或 tag 超出标签位的范围时返回错误，其他出错情况同 NextID
+// 同一生成器不同标签的 ID 共用序号，不会重复，仍按生成顺序严格递增
+func (s *SnowFlake) NextIDTagged(tag uint8) (int64, error) {
+	if s.layout.tagMask == 0 {
+		return 0, errNoTagBits
+	}
+	if int64(tag) > s.layout.tagMask {
+		return 0, fmt.Errorf("snowflake: tag %d out of range [0, %d]", tag, s.layout.tagMask)
+	}
+
+	id, err := s.NextID()
+	if err != nil {
+		return 0, err
+	}
+	return id | int64(tag), nil
+}
+
+// MustNextIDTagged 同 NextIDTagged，但出错时 panic
+func (s *SnowFlake) MustNextIDTagged(tag uint8) int64 {
+	id, err := s.NextIDTagged(tag)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
@@ -0,0 +1,66 @@
+package snowflake_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestNextIDTagged(t *testing.T) {
+	cfg := snowflake.Config{TimestampBits: 41, DataCenterBits: 5, WorkerBits: 5, SequenceBits: 10, TagBits: 2}
+	sf, err := snowflake.NewWithConfig(cfg, testStartTime, 3, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var last int64
+	for i := 0; i < 100; i++ {
+		tag := uint8(i % 4)
+		id := sf.MustNextIDTagged(tag)
+		if id <= last {
+			t.Fatalf("id %d not greater than previous %d", id, last)
+		}
+		last = id
+
+		p := sf.Decompose(id)
+		if p.Tag != tag || p.DataCenterID != 3 || p.WorkerID != 7 {
+			t.Errorf("Decompose(%d) = %+v, want tag %d dc 3 worker 7", id, p, tag)
+		}
+	}
+
+	// NextID 生成的 ID 标签为 0，序号与 NextIDTagged 共用
+	p := sf.Decompose(sf.MustNextID())
+	if p.Tag != 0 {
+		t.Errorf("Tag = %d, want 0", p.Tag)
+	}
+
+	if _, err := sf.NextIDTagged(4); err == nil {
+		t.Error("NextIDTagged(4) with 2 tag bits should return an error")
+	}
+	if _, err := snowflake.New().NextIDTagged(0); err == nil {
+		t.Error("NextIDTagged on a layout without tag bits should return an error")
+	}
+
+	if s := sf.Decompose(sf.MustNextIDTagged(2)).String(); !strings.HasSuffix(s, " tag=2") {
+		t.Errorf("String() = %q, want a tag=2 suffix", s)
+	}
+	if got := snowflake.TagMask(cfg); got != 3 {
+		t.Errorf("TagMask = %d, want 3", got)
+	}
+	if got := snowflake.SequenceShift(cfg); got != 2 {
+		t.Errorf("SequenceShift = %d, want 2", got)
+	}
+}
+
+func TestTagBitsValidate(t *testing.T) {
+	// 标签位计入总位数，最多 8 位
+	for _, cfg := range []snowflake.Config{
+		{TimestampBits: 41, DataCenterBits: 5, WorkerBits: 5, SequenceBits: 12, TagBits: 1},
+		{TimestampBits: 41, DataCenterBits: 1, WorkerBits: 1, SequenceBits: 11, TagBits: 9},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should return an error", cfg)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package snowflake
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ToULID 将 ID 无损地嵌入为 ULID 格式的 128 位标识：前 6 字节（48 位）是 ID 生成时间的 Unix 毫秒时间戳（大端序），
+// 与 ULID 一致，按字节序排序即按时间排序；ULID 中 80 位随机数的部分不再是随机数，
+// 而是 2 个 0 字节加上 8 字节大端序的原始 ID（包含节点和序号），同一毫秒内的顺序与 ID 的顺序一致
+// 时间戳按本 SnowFlake 的开始时间计算，需要用同样配置的生成器的 FromULID 解码
+func (s *SnowFlake) ToULID(id int64) [16]byte {
+	var u [16]byte
+	ms := uint64(s.TimestampMsOf(id))
+	binary.BigEndian.PutUint16(u[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(u[2:6], uint32(ms))
+	binary.BigEndian.PutUint64(u[8:], uint64(id))
+	return u
+}
+
+// FromULID 从 ToULID 生成的 128 位标识中取回 ID，时间戳与 ID 不一致或中间的 2 字节不为 0 时返回错误，
+// 真正随机生成的 ULID 几乎总会返回错误
+func (s *SnowFlake) FromULID(u [16]byte) (int64, error) {
+	id := int64(binary.BigEndian.Uint64(u[8:]))
+	if u[6] != 0 || u[7] != 0 || s.ToULID(id) != u {
+		return 0, errors.New("snowflake: not a ULID embedding of a snowflake id")
+	}
+	return id, nil
+}
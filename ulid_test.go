@@ -0,0 +1,47 @@
+package snowflake_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestULID(t *testing.T) {
+	now := testNow.Add(456 * time.Millisecond)
+	sf := snowflake.NewWithClock(testStartTime, newFakeClock(now), 1, 2)
+	a, b := sf.MustNextID(), sf.MustNextID()
+
+	ua, ub := sf.ToULID(a), sf.ToULID(b)
+	// 前 48 位是 Unix 毫秒时间戳，与 ULID 一致
+	if got := uint64(binary.BigEndian.Uint16(ua[0:2]))<<32 | uint64(binary.BigEndian.Uint32(ua[2:6])); got != uint64(now.UnixMilli()) {
+		t.Errorf("ULID timestamp = %d, want %d", got, now.UnixMilli())
+	}
+	if bytes.Compare(ua[:], ub[:]) >= 0 {
+		t.Errorf("ToULID(%d) should sort before ToULID(%d)", a, b)
+	}
+
+	for _, id := range []int64{a, b, 0} {
+		got, err := sf.FromULID(sf.ToULID(id))
+		if err != nil {
+			t.Fatalf("FromULID(ToULID(%d)) error: %v", id, err)
+		}
+		if got != id {
+			t.Errorf("FromULID(ToULID(%d)) = %d", id, got)
+		}
+	}
+
+	// 时间戳被篡改、中间字节不为 0
+	tampered := ua
+	tampered[5]++
+	if _, err := sf.FromULID(tampered); err == nil {
+		t.Error("FromULID with a mismatched timestamp should return an error")
+	}
+	tampered = ua
+	tampered[7] = 1
+	if _, err := sf.FromULID(tampered); err == nil {
+		t.Error("FromULID with random bytes should return an error")
+	}
+}
@@ -0,0 +1,23 @@
+package snowflake
+
+// Validate 检查生成器的配置是否合理，返回发现的第一个问题，适合在服务启动的健康检查中调用：
+//  1. 位分配合法，各部分之和为 63（uint64 位分配为 64），见 Config.Validate
+//  2. dataCenterID 和 workerID 在位分配的范围内
+//  3. 开始时间不晚于时间源的当前时间
+//  4. dataCenterID 和 workerID 不是自动获取得到的 0, 0：这通常说明获取机器 ID 失败了，
+//     显式指定的 0, 0（如 NewWith(startTime, 0, 0)、WithDataCenterID(0) 加 WithWorkerID(0)）不算问题
+func (s *SnowFlake) Validate() error {
+	if err := s.layout.config.Validate(); err != nil {
+		return err
+	}
+	if err := s.layout.checkNode(s.dataCenterID, s.workerID); err != nil {
+		return err
+	}
+	if now := s.clock.Now(); s.startTime.After(now) {
+		return futureStartTimeError(s.startTime, now)
+	}
+	if !s.explicitNode && s.dataCenterID == 0 && s.workerID == 0 {
+		return ErrZeroMachineID
+	}
+	return nil
+}
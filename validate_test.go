@@ -0,0 +1,42 @@
+package snowflake_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestSnowFlakeValidate(t *testing.T) {
+	if err := snowflake.NewWithClock(testStartTime, newFakeClock(testNow), 1, 2).Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+
+	// 显式指定的 0, 0 没有问题
+	if err := snowflake.NewWith(testStartTime, 0, 0).Validate(); err != nil {
+		t.Errorf("Validate() with explicit 0, 0 = %v, want nil", err)
+	}
+	if err := snowflake.New(snowflake.WithDataCenterID(0), snowflake.WithWorkerID(0)).Validate(); err != nil {
+		t.Errorf("Validate() with explicit 0, 0 options = %v, want nil", err)
+	}
+
+	// 自动获取得到的 0, 0
+	sf := snowflake.New(snowflake.WithMachineIDProvider(stubProvider{err: errors.New("no network")}))
+	if err := sf.Validate(); !errors.Is(err, snowflake.ErrZeroMachineID) {
+		t.Errorf("Validate() with a resolved 0, 0 node = %v, want ErrZeroMachineID", err)
+	}
+	sf, err := snowflake.NewWithProvider(testStartTime, stubProvider{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sf.Validate(); !errors.Is(err, snowflake.ErrZeroMachineID) {
+		t.Errorf("Validate() with a provider returning 0, 0 = %v, want ErrZeroMachineID", err)
+	}
+
+	// 开始时间晚于当前时间
+	sf = snowflake.NewWithClock(testNow.Add(time.Hour), newFakeClock(testNow), 1, 2)
+	if err := sf.Validate(); !errors.Is(err, snowflake.ErrFutureStartTime) {
+		t.Errorf("Validate() = %v, want ErrFutureStartTime", err)
+	}
+}
@@ -0,0 +1,39 @@
+package snowflake
+
+import (
+	"runtime"
+	"time"
+)
+
+// WaitStrategy 当前毫秒内序号用完、等待时钟前进时的策略
+type WaitStrategy int
+
+const (
+	// WaitYield 每次检查时钟前调用 runtime.Gosched 让出 CPU，默认策略
+	WaitYield WaitStrategy = iota
+	// WaitBusy 不停地检查时钟，延迟最低，但会占满一个 CPU 核
+	WaitBusy
+	// WaitSleep 每次检查时钟前休眠 sleepInterval，CPU 占用最低，但唤醒会有延迟
+	WaitSleep
+)
+
+// sleepInterval WaitSleep 每次休眠的时长
+const sleepInterval = 100 * time.Microsecond
+
+// SetWaitStrategy 设置等待时钟前进时的策略，默认为 WaitYield
+func (s *SnowFlake) SetWaitStrategy(ws WaitStrategy) {
+	s.mutex.Lock()
+	s.waitStrategy = ws
+	s.mutex.Unlock()
+}
+
+// pause 按等待策略暂停一次
+func (s *SnowFlake) pause() {
+	switch s.waitStrategy {
+	case WaitBusy:
+	case WaitSleep:
+		time.Sleep(sleepInterval)
+	default:
+		runtime.Gosched()
+	}
+}
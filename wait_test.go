@@ -0,0 +1,43 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package snowflake_test
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+// cpuTime 返回进程已使用的 CPU 时间（用户态 + 内核态）
+func cpuTime(b *testing.B) time.Duration {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		b.Fatal(err)
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+}
+
+// benchmarkWaitStrategy 并发生成远超 4096 个/毫秒的 ID，使生成器持续处于序号用完的等待中，
+// 同时报告每个 ID 消耗的 CPU 时间
+func benchmarkWaitStrategy(b *testing.B, ws snowflake.WaitStrategy) {
+	sf := snowflake.NewWith(testStartTime, 1, 2)
+	sf.SetWaitStrategy(ws)
+
+	start := cpuTime(b)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			sf.MustNextID()
+		}
+	})
+	b.StopTimer()
+
+	b.ReportMetric(float64(cpuTime(b)-start)/float64(b.N), "cpu-ns/op")
+}
+
+func BenchmarkWaitBusy(b *testing.B)  { benchmarkWaitStrategy(b, snowflake.WaitBusy) }
+func BenchmarkWaitYield(b *testing.B) { benchmarkWaitStrategy(b, snowflake.WaitYield) }
+func BenchmarkWaitSleep(b *testing.B) { benchmarkWaitStrategy(b, snowflake.WaitSleep) }
@@ -0,0 +1,55 @@
+package snowflake
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// writeChunk WriteN 每次加锁生成的 ID 数
+const writeChunk = 4096
+
+// WriteN 生成 n 个 ID，以十进制写入 w，ID 之间用 sep 分隔（如 ',' 或 '\t'，'\n' 则每行一个），最后写入一个换行符
+// 内部使用带缓冲的 Writer，每次加锁生成一批，不会在内存中保存全部 ID，适合导出数百万个 ID
+// 返回写入的 ID 数；写入失败或生成出错（时间戳溢出等）时停止，错误中说明写入了多少个，可以用 errors.Is 判断原始错误
+func (s *SnowFlake) WriteN(w io.Writer, n int, sep byte) (int, error) {
+	bw := bufio.NewWriter(w)
+	ids := make([]int64, writeChunk)
+	buf := make([]byte, 0, 20)
+
+	written := 0
+	for written < n {
+		chunk := ids
+		if n-written < len(chunk) {
+			chunk = chunk[:n-written]
+		}
+		filled, genErr := s.fill(chunk)
+
+		for _, id := range chunk[:filled] {
+			if written > 0 {
+				bw.WriteByte(sep)
+			}
+			buf = strconv.AppendInt(buf[:0], id, 10)
+			if _, err := bw.Write(buf); err != nil {
+				return written, fmt.Errorf("snowflake: wrote %d of %d ids: %w", written, n, err)
+			}
+			written++
+		}
+
+		if genErr != nil {
+			if err := bw.Flush(); err != nil {
+				return written, fmt.Errorf("snowflake: wrote %d of %d ids: %w", written, n, err)
+			}
+			return written, fmt.Errorf("snowflake: wrote %d of %d ids: %w", written, n, genErr)
+		}
+	}
+
+	if n > 0 {
+		bw.WriteByte('\n')
+	}
+	if err := bw.Flush(); err != nil {
+		return written, fmt.Errorf("snowflake: wrote %d of %d ids: %w", written, n, err)
+	}
+	return written, nil
+}
@@ -0,0 +1,84 @@
+package snowflake_test
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/polaris1119/snowflake"
+)
+
+func TestWriteN(t *testing.T) {
+	sf := snowflake.NewWith(testStartTime, 1, 2)
+
+	var buf bytes.Buffer
+	n, err := sf.WriteN(&buf, 10000, ',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 10000 {
+		t.Errorf("WriteN() = %d, want 10000", n)
+	}
+
+	out := buf.String()
+	if !strings.HasSuffix(out, "\n") || strings.Count(out, "\n") != 1 {
+		t.Fatalf("output should end with a single newline, got %q...", out[len(out)-20:])
+	}
+	fields := strings.Split(strings.TrimSuffix(out, "\n"), ",")
+	if len(fields) != 10000 {
+		t.Fatalf("got %d fields, want 10000", len(fields))
+	}
+	ids := make([]int64, len(fields))
+	for i, f := range fields {
+		if ids[i], err = strconv.ParseInt(f, 10, 64); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !snowflake.IsMonotonic(ids) {
+		t.Error("written ids are not strictly increasing")
+	}
+
+	buf.Reset()
+	if n, err := sf.WriteN(&buf, 0, '\t'); n != 0 || err != nil || buf.Len() != 0 {
+		t.Errorf("WriteN(0) = %d, %v, wrote %q, want nothing", n, err, buf.String())
+	}
+}
+
+// failWriter 写入超过 limit 字节后返回错误
+type failWriter struct {
+	limit int
+}
+
+var errWriteFailed = errors.New("write failed")
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		n := w.limit
+		w.limit = 0
+		return n, errWriteFailed
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestWriteNErrors(t *testing.T) {
+	sf := snowflake.NewWith(testStartTime, 1, 2)
+	if _, err := sf.WriteN(&failWriter{limit: 100}, 100000, '\n'); !errors.Is(err, errWriteFailed) {
+		t.Errorf("err = %v, want the write error", err)
+	}
+
+	// 时间戳溢出时写出已生成的部分
+	last := testStartTime.Add(time.Duration(1<<41-1) * time.Millisecond)
+	sf = snowflake.NewWithClock(testStartTime, newFakeClock(append(repeatTime(last, 4096), last.Add(time.Millisecond))...), 1, 2)
+	var buf bytes.Buffer
+	n, err := sf.WriteN(&buf, 5000, '\n')
+	if !errors.Is(err, snowflake.ErrTimestampOverflow) {
+		t.Fatalf("err = %v, want ErrTimestampOverflow", err)
+	}
+	if n != 4096 || strings.Count(buf.String(), "\n") != 4095 {
+		t.Errorf("WriteN() = %d with %d separators, want 4096 ids", n, strings.Count(buf.String(), "\n"))
+	}
+}